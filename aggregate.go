@@ -24,7 +24,7 @@ func (a Aggregate) EQ(arg any) Predicate {
 	return Predicate{
 		left:  a,
 		op:    opEQ,
-		right: Value{val: arg},
+		right: valueOf(arg),
 	}
 }
 
@@ -32,7 +32,7 @@ func (a Aggregate) LT(arg any) Predicate {
 	return Predicate{
 		left:  a,
 		op:    opLT,
-		right: Value{val: arg},
+		right: valueOf(arg),
 	}
 }
 
@@ -40,7 +40,21 @@ func (a Aggregate) GT(arg any) Predicate {
 	return Predicate{
 		left:  a,
 		op:    opGT,
-		right: Value{val: arg},
+		right: valueOf(arg),
+	}
+}
+
+func (a Aggregate) Asc() OrderBy {
+	return OrderBy{
+		expr:  a,
+		order: "ASC",
+	}
+}
+
+func (a Aggregate) Desc() OrderBy {
+	return OrderBy{
+		expr:  a,
+		order: "DESC",
 	}
 }
 
@@ -0,0 +1,99 @@
+package toyorm
+
+// CaseBuilder 用于构造 CASE WHEN 表达式，
+// 例如 Case().When(Col("Age").LT(18), "child").Else("adult").End()
+type CaseBuilder struct {
+	whens []caseWhen
+	els   Expression
+}
+
+type caseWhen struct {
+	when Predicate
+	then Expression
+}
+
+func Case() CaseBuilder {
+	return CaseBuilder{}
+}
+
+// When val 可以是 Expression，例如 Col("Age")，其余的作为参数
+func (c CaseBuilder) When(p Predicate, val any) CaseBuilder {
+	whens := make([]caseWhen, 0, len(c.whens)+1)
+	whens = append(whens, c.whens...)
+	return CaseBuilder{
+		whens: append(whens, caseWhen{when: p, then: valueOf(val)}),
+		els:   c.els,
+	}
+}
+
+func (c CaseBuilder) Else(val any) CaseBuilder {
+	return CaseBuilder{
+		whens: c.whens,
+		els:   valueOf(val),
+	}
+}
+
+func (c CaseBuilder) End() CaseExpr {
+	return CaseExpr{
+		whens: c.whens,
+		els:   c.els,
+	}
+}
+
+// CaseExpr CASE WHEN ... THEN ... ELSE ... END 表达式，
+// 可以用在 Select、Where、GroupBy、OrderBy 以及 Assign 里面
+type CaseExpr struct {
+	whens []caseWhen
+	els   Expression
+	alias string
+}
+
+func (c CaseExpr) Expr() {}
+
+func (c CaseExpr) selectable() {}
+
+func (c CaseExpr) AS(alias string) CaseExpr {
+	return CaseExpr{
+		whens: c.whens,
+		els:   c.els,
+		alias: alias,
+	}
+}
+
+func (c CaseExpr) EQ(val any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opEQ,
+		right: valueOf(val),
+	}
+}
+
+func (c CaseExpr) GT(val any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opGT,
+		right: valueOf(val),
+	}
+}
+
+func (c CaseExpr) LT(val any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opLT,
+		right: valueOf(val),
+	}
+}
+
+func (c CaseExpr) Asc() OrderBy {
+	return OrderBy{
+		expr:  c,
+		order: "ASC",
+	}
+}
+
+func (c CaseExpr) Desc() OrderBy {
+	return OrderBy{
+		expr:  c,
+		order: "DESC",
+	}
+}
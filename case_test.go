@@ -0,0 +1,86 @@
+package toyorm
+
+import (
+	"testing"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseExpr(t *testing.T) {
+	db := memoryDB(t)
+	bucket := Case().
+		When(Col("Age").LT(18), "child").
+		When(Col("Age").LT(60), "adult").
+		Else("senior").End()
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "select",
+			q:    NewSelector[TestModel](db).Select(Col("Id"), bucket.AS("bucket")),
+			wantQuery: &Query{
+				SQL:  "SELECT `id`, CASE WHEN `age` < ? THEN ? WHEN `age` < ? THEN ? ELSE ? END AS `bucket` FROM `test_model`;",
+				Args: []any{18, "child", 60, "adult", "senior"},
+			},
+		},
+		{
+			name: "where",
+			q:    NewSelector[TestModel](db).Where(bucket.EQ("adult"), Col("Id").GT(10)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (CASE WHEN `age` < ? THEN ? WHEN `age` < ? THEN ? ELSE ? END = ?) AND (`id` > ?);",
+				Args: []any{18, "child", 60, "adult", "senior", "adult", 10},
+			},
+		},
+		{
+			name: "group by",
+			q:    NewSelector[TestModel](db).Select(Count("Id")).GroupBy(bucket),
+			wantQuery: &Query{
+				SQL:  "SELECT COUNT(`id`) FROM `test_model` GROUP BY CASE WHEN `age` < ? THEN ? WHEN `age` < ? THEN ? ELSE ? END;",
+				Args: []any{18, "child", 60, "adult", "senior"},
+			},
+		},
+		{
+			// THEN 也可以是列
+			name: "order by",
+			q:    NewSelector[TestModel](db).OrderBy(Case().When(Col("Age").GT(0), Col("Age")).End().Desc()),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` ORDER BY CASE WHEN `age` > ? THEN `age` END DESC;",
+				Args: []any{0},
+			},
+		},
+		{
+			name: "update assign",
+			q: NewUpdater[TestModel](db).Update(&TestModel{}).
+				Set(Assign("FirstName", Case().When(Col("Age").LT(18), "kid").Else(Col("FirstName")).End())),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=CASE WHEN `age` < ? THEN ? ELSE `first_name` END;",
+				Args: []any{18, "kid"},
+			},
+		},
+		{
+			name:    "no when",
+			q:       NewSelector[TestModel](db).Select(Case().Else(1).End()),
+			wantErr: errs.ErrEmptyCase,
+		},
+		{
+			name:    "invalid column",
+			q:       NewSelector[TestModel](db).Select(Case().When(Col("Invalid").EQ(1), 1).End()),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
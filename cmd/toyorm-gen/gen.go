@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"go.uber.org/multierr"
+)
+
+// model 需要生成代码的模型
+type model struct {
+	Name   string
+	Fields []*field
+}
+
+// field 展开之后的字段，和 Registry 解析出来的字段一一对应
+type field struct {
+	// Name Registry 里面的字段名，展开的具名结构体字段是 Addr.City 的形式
+	Name string
+	// Path 从模型访问字段的表达式，例如 BaseModel.Id
+	Path string
+	// Pointer 字段是不是指针，nil 指针写入 NULL
+	Pointer bool
+
+	depth int
+}
+
+// generate 解析 dir 里面的包，为 typeNames 生成 Accessor 的代码
+func generate(dir string, typeNames []string, output string, tests bool) ([]byte, error) {
+	pkg, err := loadPackage(dir, output, tests)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*model, 0, len(typeNames))
+	for _, name := range typeNames {
+		m, err := parseModel(pkg, strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, struct {
+		Package string
+		Models  []*model
+	}{Package: pkg.Name(), Models: models}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// loadPackage 用 go/types 检查 dir 里面的包，跳过之前生成的 output。
+// 依赖的包检查失败的时候不直接返回错误，只要模型的字段类型是完整的就可以生成
+func loadPackage(dir string, output string, tests bool) (*types.Package, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := bp.GoFiles
+	if tests {
+		names = append(names, bp.TestGoFiles...)
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		if name == output {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s 里面没有 Go 文件", dir)
+	}
+
+	var typeErrs []error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			typeErrs = append(typeErrs, err)
+		},
+	}
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, nil)
+	if pkg == nil {
+		return nil, multierr.Combine(typeErrs...)
+	}
+	return pkg, nil
+}
+
+func parseModel(pkg *types.Package, name string) (*model, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("找不到类型 %s", name)
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("%s 必须是非泛型的结构体", name)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("%s 必须是非泛型的结构体", name)
+	}
+	fields, err := parseFields(pkg, st, fieldPath{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	fields, err = resolveFields(fields)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &model{Name: name, Fields: fields}, nil
+}
+
+type fieldPath struct {
+	name  string
+	path  string
+	depth int
+}
+
+// parseFields 和 Registry 一样展开匿名嵌入的结构体和标记了 embedded 的结构体
+func parseFields(pkg *types.Package, st *types.Struct, path fieldPath) ([]*field, error) {
+	res := make([]*field, 0, st.NumFields())
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		if v.Type() == types.Typ[types.Invalid] || !validType(v.Type()) {
+			return nil, fmt.Errorf("无法解析字段 %s%s 的类型", path.name, v.Name())
+		}
+		if !v.Exported() && v.Pkg() != pkg {
+			return nil, fmt.Errorf("无法访问其它包的字段 %s%s", path.name, v.Name())
+		}
+		_, isEmbedded := ormTags(st.Tag(i))["embedded"]
+		if v.Anonymous() {
+			if _, ok := v.Type().Underlying().(*types.Pointer); ok {
+				// Registry 会返回错误，这里不需要生成
+				return nil, fmt.Errorf("不支持嵌入指针 %s%s", path.name, v.Name())
+			}
+		}
+		if v.Anonymous() && isFlattenable(v.Type()) || isEmbedded {
+			sub, ok := v.Type().Underlying().(*types.Struct)
+			if !ok {
+				return nil, fmt.Errorf("%s%s 不是结构体，不能展开", path.name, v.Name())
+			}
+			next := fieldPath{
+				name:  path.name,
+				path:  path.path + v.Name() + ".",
+				depth: path.depth + 1,
+			}
+			if !v.Anonymous() {
+				next.name = path.name + v.Name() + "."
+			}
+			fields, err := parseFields(pkg, sub, next)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, fields...)
+			continue
+		}
+		_, isPtr := v.Type().Underlying().(*types.Pointer)
+		res = append(res, &field{
+			Name:    path.name + v.Name(),
+			Path:    path.path + v.Name(),
+			Pointer: isPtr,
+			depth:   path.depth,
+		})
+	}
+	return res, nil
+}
+
+// resolveFields 层数少的字段覆盖层数多的，只有层数最少的那一层有多个同名字段才有歧义，
+// 和 Registry 的规则一样
+func resolveFields(fields []*field) ([]*field, error) {
+	minDepth := make(map[string]int, len(fields))
+	for _, f := range fields {
+		if depth, ok := minDepth[f.Name]; !ok || f.depth < depth {
+			minDepth[f.Name] = f.depth
+		}
+	}
+	byName := make(map[string]*field, len(minDepth))
+	for _, f := range fields {
+		if f.depth != minDepth[f.Name] {
+			continue
+		}
+		if _, ok := byName[f.Name]; ok {
+			return nil, fmt.Errorf("字段 %s 有歧义", f.Name)
+		}
+		byName[f.Name] = f
+	}
+	res := make([]*field, 0, len(byName))
+	for _, f := range fields {
+		if byName[f.Name] == f {
+			res = append(res, f)
+		}
+	}
+	return res, nil
+}
+
+// isFlattenable 和 Registry 一样，time.Time 以及实现了 sql.Scanner 的结构体不展开
+func isFlattenable(typ types.Type) bool {
+	if _, ok := typ.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	if named, ok := typ.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return false
+		}
+	}
+	scan, _, _ := types.LookupFieldOrMethod(types.NewPointer(typ), true, nil, "Scan")
+	_, isMethod := scan.(*types.Func)
+	return !isMethod
+}
+
+// validType 检查类型里面有没有因为依赖的包检查失败而无法解析的部分
+func validType(typ types.Type) bool {
+	switch t := typ.(type) {
+	case *types.Basic:
+		return t.Kind() != types.Invalid
+	case *types.Pointer:
+		return validType(t.Elem())
+	case *types.Slice:
+		return validType(t.Elem())
+	case *types.Array:
+		return validType(t.Elem())
+	case *types.Map:
+		return validType(t.Key()) && validType(t.Elem())
+	case *types.Named:
+		args := t.TypeArgs()
+		for i := 0; i < args.Len(); i++ {
+			if !validType(args.At(i)) {
+				return false
+			}
+		}
+		return validType(t.Underlying())
+	}
+	return true
+}
+
+// ormTags 解析 orm 标签，只需要知道有哪些 key
+func ormTags(tag string) map[string]string {
+	res := make(map[string]string)
+	ormTag, ok := reflect.StructTag(tag).Lookup("orm")
+	if !ok {
+		return res
+	}
+	for _, pair := range strings.Split(ormTag, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			continue
+		}
+		if len(kv) == 2 {
+			res[key] = strings.TrimSpace(kv[1])
+		} else {
+			res[key] = ""
+		}
+	}
+	return res
+}
+
+var tpl = template.Must(template.New("accessor").Parse(`// Code generated by toyorm-gen. DO NOT EDIT.
+
+package {{.Package}}
+{{range .Models}}
+// ToyormFieldNames 由 toyorm-gen 生成
+func (t *{{.Name}}) ToyormFieldNames() []string {
+	return []string{
+	{{- range .Fields}}
+		"{{.Name}}",
+	{{- end}}
+	}
+}
+
+// ToyormField 由 toyorm-gen 生成
+func (t *{{.Name}}) ToyormField(i int) any {
+	switch i {
+	{{- range $i, $f := .Fields}}
+	case {{$i}}:
+		{{- if $f.Pointer}}
+		if t.{{$f.Path}} == nil {
+			return nil
+		}
+		{{- end}}
+		return t.{{$f.Path}}
+	{{- end}}
+	}
+	return nil
+}
+
+// ToyormFieldPtr 由 toyorm-gen 生成
+func (t *{{.Name}}) ToyormFieldPtr(i int) any {
+	switch i {
+	{{- range $i, $f := .Fields}}
+	case {{$i}}:
+		return &t.{{$f.Path}}
+	{{- end}}
+	}
+	return nil
+}
+{{end}}`))
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 里面的 golden 文件")
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("testdata/models", []string{"User", "Order", "Shadowed"}, "user_toyorm.go", false)
+	require.NoError(t, err)
+	const golden = "testdata/models.golden"
+	if *update {
+		require.NoError(t, os.WriteFile(golden, src, 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(src))
+}
+
+func TestGenerate_Error(t *testing.T) {
+	testCases := []struct {
+		name    string
+		typ     string
+		wantErr error
+	}{
+		{
+			name:    "not found",
+			typ:     "Invalid",
+			wantErr: errors.New("找不到类型 Invalid"),
+		},
+		{
+			name:    "ambiguous",
+			typ:     "Ambiguous",
+			wantErr: errors.New("Ambiguous: 字段 Id 有歧义"),
+		},
+		{
+			name:    "generic",
+			typ:     "Generic",
+			wantErr: errors.New("Generic 必须是非泛型的结构体"),
+		},
+		{
+			name:    "embedded pointer",
+			typ:     "EmbeddedPointer",
+			wantErr: errors.New("EmbeddedPointer: 不支持嵌入指针 BaseModel"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := generate("testdata/models", []string{tc.typ}, "", false)
+			require.Error(t, err)
+			assert.Equal(t, tc.wantErr.Error(), err.Error())
+		})
+	}
+}
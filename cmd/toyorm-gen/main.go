@@ -0,0 +1,63 @@
+// toyorm-gen 为模型生成不使用反射的 Accessor，Registry 会自动使用生成的代码读写字段。
+//
+// 在模型所在的包里面加上
+//
+//	//go:generate toyorm-gen -type=User,Order
+//
+// 然后运行 go generate。默认输出到第一个类型的小写名字加上 _toyorm.go，例如 user_toyorm.go。
+// 修改了模型的字段之后需要重新生成，否则 Registry 解析模型的时候会返回错误。
+//
+// 注意 toyorm-gen 不知道运行时通过 DBWithConverter 注册的类型，
+// 匿名嵌入的结构体如果注册了 Converter，Registry 不会展开，会和生成的代码对不上。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "逗号分隔的模型名字，必填")
+		output    = flag.String("output", "", "输出的文件，默认是第一个类型的小写名字加上 _toyorm.go")
+		tests     = flag.Bool("tests", false, "同时解析包里面的 _test.go 文件，用于测试里面定义的模型")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "用法: toyorm-gen -type=User,Order [-output=file] [-tests] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	out := *output
+	if out == "" {
+		out = strings.ToLower(types[0]) + "_toyorm.go"
+		if *tests {
+			out = strings.ToLower(types[0]) + "_toyorm_test.go"
+		}
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+
+	src, err := generate(dir, types, filepath.Base(out), *tests)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "toyorm-gen: %v\n", err)
+		os.Exit(1)
+	}
+	if err = os.WriteFile(out, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "toyorm-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/aristletl/toyorm"
+)
+
+type BaseModel struct {
+	Id        int64
+	CreatedAt time.Time
+}
+
+type Address struct {
+	City   string
+	Street string
+}
+
+type User struct {
+	BaseModel
+	// Id 覆盖 BaseModel.Id
+	Id       uint64
+	Name     string
+	Nickname *string
+	Email    sql.NullString
+	Age      toyorm.Null[int]
+	Home     Address `orm:"embedded,prefix=home_"`
+	secret   []byte
+}
+
+type Order struct {
+	Id     int64
+	UserId int64
+	Paid   *time.Time
+}
+
+type Other struct {
+	Id int64
+}
+
+type Ambiguous struct {
+	BaseModel
+	Other
+}
+
+// Shadowed 同一层的 Id 有歧义，但是外层的 Id 覆盖了它们
+type Shadowed struct {
+	BaseModel
+	Other
+	Id int32
+}
+
+type Generic[T any] struct {
+	Id  int64
+	Val T
+}
+
+type EmbeddedPointer struct {
+	*BaseModel
+}
@@ -1,6 +1,8 @@
 package toyorm
 
 type Column struct {
+	// table 为空的时候使用 Selector 本身的模型
+	table TableReference
 	name  string
 	alias string
 }
@@ -19,7 +21,7 @@ func (c Column) EQ(val any) Predicate {
 	return Predicate{
 		left:  c,
 		op:    opEQ,
-		right: Value{val: val},
+		right: valueOf(val),
 	}
 }
 
@@ -27,7 +29,7 @@ func (c Column) GT(val any) Predicate {
 	return Predicate{
 		left:  c,
 		op:    opGT,
-		right: Value{val: val},
+		right: valueOf(val),
 	}
 }
 
@@ -35,12 +37,37 @@ func (c Column) LT(val any) Predicate {
 	return Predicate{
 		left:  c,
 		op:    opLT,
-		right: Value{val: val},
+		right: valueOf(val),
+	}
+}
+
+// In 例如 C("Id").In(1, 2, 3)
+func (c Column) In(vals ...any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opIN,
+		right: valueList{vals: vals},
+	}
+}
+
+// IsNull 例如 C("DeletedAt").IsNull()
+func (c Column) IsNull() Predicate {
+	return Predicate{
+		left: c,
+		op:   opIsNull,
+	}
+}
+
+func (c Column) IsNotNull() Predicate {
+	return Predicate{
+		left: c,
+		op:   opIsNotNull,
 	}
 }
 
 func (c Column) AS(alias string) Column {
 	return Column{
+		table: c.table,
 		name:  c.name,
 		alias: alias,
 	}
@@ -50,13 +77,46 @@ func (c Column) Add(arg int) Predicate {
 	return Predicate{
 		left:  c,
 		op:    opADD,
-		right: Value{val: arg},
+		right: valueOf(arg),
+	}
+}
+
+// Asc 按照列升序排列，等价于 Asc(name)，但是可以带上表
+func (c Column) Asc() OrderBy {
+	return OrderBy{
+		expr:  c,
+		order: "ASC",
 	}
 }
 
+func (c Column) Desc() OrderBy {
+	return OrderBy{
+		expr:  c,
+		order: "DESC",
+	}
+}
+
+// OrderBy 排序项，可以是字段，也可以是任意的表达式
 type OrderBy struct {
-	col   string
+	col string
+	// expr 不为空的时候按照表达式排序，例如窗口函数
+	expr  Expression
 	order string
+	nulls string
+}
+
+func (o OrderBy) Expr() {}
+
+// NullsFirst NULL 值排在前面，不支持的方言会用 IS NULL 模拟
+func (o OrderBy) NullsFirst() OrderBy {
+	o.nulls = "FIRST"
+	return o
+}
+
+// NullsLast NULL 值排在后面，不支持的方言会用 IS NULL 模拟
+func (o OrderBy) NullsLast() OrderBy {
+	o.nulls = "LAST"
+	return o
 }
 
 func Asc(col string) OrderBy {
@@ -72,3 +132,47 @@ func Desc(col string) OrderBy {
 		order: "DESC",
 	}
 }
+
+// toOrderBys 没有指定顺序的表达式使用数据库默认的顺序
+func toOrderBys(es []Expression) []OrderBy {
+	if len(es) == 0 {
+		return nil
+	}
+	res := make([]OrderBy, 0, len(es))
+	for _, e := range es {
+		if o, ok := e.(OrderBy); ok {
+			res = append(res, o)
+			continue
+		}
+		res = append(res, OrderBy{expr: e})
+	}
+	return res
+}
+
+// SelectAlias 引用 SELECT 列表中的别名，
+// 例如 OrderBy(Alias("avg_age").Desc())
+type SelectAlias struct {
+	name string
+}
+
+func Alias(name string) SelectAlias {
+	return SelectAlias{
+		name: name,
+	}
+}
+
+func (a SelectAlias) Expr() {}
+
+func (a SelectAlias) Asc() OrderBy {
+	return OrderBy{
+		expr:  a,
+		order: "ASC",
+	}
+}
+
+func (a SelectAlias) Desc() OrderBy {
+	return OrderBy{
+		expr:  a,
+		order: "DESC",
+	}
+}
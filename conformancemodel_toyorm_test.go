@@ -0,0 +1,134 @@
+// Code generated by toyorm-gen. DO NOT EDIT.
+
+package toyorm
+
+// ToyormFieldNames 由 toyorm-gen 生成
+func (t *ConformanceModel) ToyormFieldNames() []string {
+	return []string{
+		"Id",
+		"CreatedAt",
+		"Name",
+		"Nickname",
+		"Balance",
+		"Score",
+		"Status",
+		"Addr.City",
+		"Addr.Street",
+		"secret",
+	}
+}
+
+// ToyormField 由 toyorm-gen 生成
+func (t *ConformanceModel) ToyormField(i int) any {
+	switch i {
+	case 0:
+		return t.BaseModel.Id
+	case 1:
+		return t.BaseModel.CreatedAt
+	case 2:
+		return t.Name
+	case 3:
+		if t.Nickname == nil {
+			return nil
+		}
+		return t.Nickname
+	case 4:
+		return t.Balance
+	case 5:
+		return t.Score
+	case 6:
+		return t.Status
+	case 7:
+		return t.Addr.City
+	case 8:
+		return t.Addr.Street
+	case 9:
+		return t.secret
+	}
+	return nil
+}
+
+// ToyormFieldPtr 由 toyorm-gen 生成
+func (t *ConformanceModel) ToyormFieldPtr(i int) any {
+	switch i {
+	case 0:
+		return &t.BaseModel.Id
+	case 1:
+		return &t.BaseModel.CreatedAt
+	case 2:
+		return &t.Name
+	case 3:
+		return &t.Nickname
+	case 4:
+		return &t.Balance
+	case 5:
+		return &t.Score
+	case 6:
+		return &t.Status
+	case 7:
+		return &t.Addr.City
+	case 8:
+		return &t.Addr.Street
+	case 9:
+		return &t.secret
+	}
+	return nil
+}
+
+// ToyormFieldNames 由 toyorm-gen 生成
+func (t *BenchModel) ToyormFieldNames() []string {
+	return []string{
+		"Id",
+		"Name",
+		"Age",
+		"Email",
+		"Nickname",
+		"Score",
+		"CreatedAt",
+	}
+}
+
+// ToyormField 由 toyorm-gen 生成
+func (t *BenchModel) ToyormField(i int) any {
+	switch i {
+	case 0:
+		return t.Id
+	case 1:
+		return t.Name
+	case 2:
+		return t.Age
+	case 3:
+		return t.Email
+	case 4:
+		if t.Nickname == nil {
+			return nil
+		}
+		return t.Nickname
+	case 5:
+		return t.Score
+	case 6:
+		return t.CreatedAt
+	}
+	return nil
+}
+
+// ToyormFieldPtr 由 toyorm-gen 生成
+func (t *BenchModel) ToyormFieldPtr(i int) any {
+	switch i {
+	case 0:
+		return &t.Id
+	case 1:
+		return &t.Name
+	case 2:
+		return &t.Age
+	case 3:
+		return &t.Email
+	case 4:
+		return &t.Nickname
+	case 5:
+		return &t.Score
+	case 6:
+		return &t.CreatedAt
+	}
+	return nil
+}
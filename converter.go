@@ -0,0 +1,45 @@
+package toyorm
+
+import (
+	"reflect"
+
+	"github.com/aristletl/toyorm/internal/model"
+)
+
+// Converter 在 Go 类型和数据库支持的类型之间转换，通过 DBWithConverter 注册。
+// 用于无法实现 sql.Scanner 和 driver.Valuer 的类型，例如 uuid.UUID 存成 BINARY(16)，
+// []string 存成逗号分隔的字符串，或者枚举存成字符串
+type Converter = model.Converter
+
+// addArg 添加一个参数，注册了 Converter 的类型会先转换成数据库的值，
+// 指向这些类型的 nil 指针转换成 NULL
+func (s *SQLBuilder) addArg(val any) error {
+	arg, err := convertArg(s.r, val)
+	if err != nil {
+		return err
+	}
+	s.args = append(s.args, arg)
+	return nil
+}
+
+func convertArg(r *model.Registry, val any) (any, error) {
+	typ := reflect.TypeOf(val)
+	if typ == nil {
+		return val, nil
+	}
+	if c, ok := r.Converter(typ); ok {
+		return c.ToDB(val)
+	}
+	if typ.Kind() != reflect.Pointer {
+		return val, nil
+	}
+	c, ok := r.Converter(typ.Elem())
+	if !ok {
+		return val, nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.IsNil() {
+		return nil, nil
+	}
+	return c.ToDB(rv.Elem().Interface())
+}
@@ -0,0 +1,224 @@
+package toyorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_Build(t *testing.T) {
+	db := memoryDB(t, converterOptions()...)
+	id := UUID{1, 2, 3}
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "insert",
+			q: NewInserter[Device](db).Values(&Device{
+				Id:     id,
+				Tags:   []string{"a", "b"},
+				Status: StatusOnline,
+			}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `device`(`id`, `tags`, `status`, `prev_status`) VALUES(?, ?, ?, ?);",
+				Args: []any{id[:], "a,b", "online", nil},
+			},
+		},
+		{
+			name: "insert map",
+			q: NewInserter[Device](db).InsertMap(map[string]any{
+				"Id":         id,
+				"Status":     StatusOffline,
+				"PrevStatus": &[]Status{StatusOnline}[0],
+			}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `device`(`id`, `status`, `prev_status`) VALUES(?, ?, ?);",
+				Args: []any{id[:], "offline", "online"},
+			},
+		},
+		{
+			name: "predicate",
+			q: NewSelector[Device](db).Where(Col("Id").EQ(id).
+				AND(Col("Status").In(StatusOnline, StatusOffline))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `device` WHERE (`id` = ?) AND (`status` IN (?, ?));",
+				Args: []any{id[:], "online", "offline"},
+			},
+		},
+		{
+			name: "raw select",
+			q:    NewSelector[Device](db).Select(Raw("`status` = ? AS `online`", StatusOnline)),
+			wantQuery: &Query{
+				SQL:  "SELECT `status` = ? AS `online` FROM `device`;",
+				Args: []any{"online"},
+			},
+		},
+		{
+			name: "update",
+			q: NewUpdater[Device](db).Update(&Device{Id: id, Tags: []string{"c"}}).
+				Set(Col("Tags"), Assign("Status", StatusOffline)).Where(Col("Id").EQ(id)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `device` SET `tags`=?, `status`=? WHERE `id` = ?;",
+				Args: []any{"c", "offline", id[:]},
+			},
+		},
+		{
+			name:    "invalid value",
+			q:       NewSelector[Device](db).Where(Col("Status").EQ(Status(100))),
+			wantErr: errInvalidStatus,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestConverter_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:converter.db?cache=shared&mode=memory",
+		append(converterOptions(), DBWithDialect(SQLite))...)
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE device(id BLOB PRIMARY KEY, tags TEXT, status TEXT, prev_status TEXT);")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	prev := StatusOffline
+	devices := []*Device{
+		{Id: UUID{1}, Tags: []string{"a", "b"}, Status: StatusOnline, PrevStatus: &prev},
+		// nil 指针写入 NULL，读出来也是 nil
+		{Id: UUID{2}, Tags: []string{}, Status: StatusOffline},
+	}
+	require.NoError(t, NewInserter[Device](db).Values(devices...).Exec(ctx).Err())
+
+	for name, creator := range valuerCreators {
+		t.Run(name, func(t *testing.T) {
+			s := NewSelector[Device](db).Where(Col("Id").In(UUID{1}, UUID{2}))
+			s.valCreator = creator
+			got, err := s.GetMulti(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, devices, got)
+		})
+	}
+
+	_, err = db.db.Exec("UPDATE device SET status = 'unknown' WHERE status = 'offline';")
+	require.NoError(t, err)
+	_, err = NewSelector[Device](db).Where(Col("Id").EQ(UUID{2})).Get(ctx)
+	assert.Equal(t, errInvalidStatus, err)
+}
+
+func converterOptions() []DBOption {
+	return []DBOption{
+		DBWithConverter[UUID](uuidConverter{}),
+		DBWithConverter[[]string](tagsConverter{}),
+		DBWithConverter[Status](statusConverter{}),
+	}
+}
+
+// UUID 模拟第三方库的类型，存成 BINARY(16)
+type UUID [16]byte
+
+type uuidConverter struct{}
+
+func (uuidConverter) ToDB(val any) (driver.Value, error) {
+	id := val.(UUID)
+	return id[:], nil
+}
+
+func (uuidConverter) FromDB(src any, dst any) error {
+	bs, ok := src.([]byte)
+	if !ok || len(bs) != 16 {
+		return fmt.Errorf("无法把 %v 转换成 UUID", src)
+	}
+	copy(dst.(*UUID)[:], bs)
+	return nil
+}
+
+// tagsConverter 把 []string 存成逗号分隔的字符串
+type tagsConverter struct{}
+
+func (tagsConverter) ToDB(val any) (driver.Value, error) {
+	return strings.Join(val.([]string), ","), nil
+}
+
+func (tagsConverter) FromDB(src any, dst any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+		*dst.(*[]string) = nil
+		return nil
+	}
+	tags := []string{}
+	if s != "" {
+		tags = strings.Split(s, ",")
+	}
+	*dst.(*[]string) = tags
+	return nil
+}
+
+type Status int
+
+const (
+	StatusOnline Status = iota + 1
+	StatusOffline
+)
+
+var (
+	errInvalidStatus = errors.New("错误的状态")
+	statusNames      = map[Status]string{StatusOnline: "online", StatusOffline: "offline"}
+)
+
+// statusConverter 把枚举存成字符串
+type statusConverter struct{}
+
+func (statusConverter) ToDB(val any) (driver.Value, error) {
+	name, ok := statusNames[val.(Status)]
+	if !ok {
+		return nil, errInvalidStatus
+	}
+	return name, nil
+}
+
+func (statusConverter) FromDB(src any, dst any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	}
+	for status, name := range statusNames {
+		if name == s {
+			*dst.(*Status) = status
+			return nil
+		}
+	}
+	return errInvalidStatus
+}
+
+type Device struct {
+	Id         UUID
+	Tags       []string
+	Status     Status
+	PrevStatus *Status
+}
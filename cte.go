@@ -0,0 +1,168 @@
+package toyorm
+
+import (
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/aristletl/toyorm/internal/model"
+)
+
+// CommonTable 代表 WITH 子句定义的公共表表达式（CTE），
+// 可以在 From 和 Join 里面当成普通表使用，例如 CTE("tree")
+type CommonTable struct {
+	name  string
+	alias string
+}
+
+func CTE(name string) CommonTable {
+	return CommonTable{
+		name: name,
+	}
+}
+
+func (c CommonTable) tableAlias() string {
+	return c.alias
+}
+
+func (c CommonTable) As(alias string) CommonTable {
+	return CommonTable{
+		name:  c.name,
+		alias: alias,
+	}
+}
+
+// C 引用 CTE 的列，name 是定义 CTE 的 Selector 所对应模型的字段名，
+// 或者是 CTE 里面列的别名
+func (c CommonTable) C(name string) Column {
+	return Column{
+		name:  name,
+		table: c,
+	}
+}
+
+func (c CommonTable) Join(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  c,
+		typ:   "JOIN",
+		right: right,
+	}
+}
+
+func (c CommonTable) LeftJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  c,
+		typ:   "LEFT JOIN",
+		right: right,
+	}
+}
+
+func (c CommonTable) RightJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  c,
+		typ:   "RIGHT JOIN",
+		right: right,
+	}
+}
+
+type cteDef struct {
+	name      string
+	anchor    SelectQuery
+	recursive SelectQuery
+}
+
+// model CTE 的列来自于 anchor 部分，所以用 anchor 的模型校验列
+func (c *cteDef) model() *model.Model {
+	return c.anchor.sqlBuilder().model
+}
+
+func (s *SQLBuilder) findCTE(name string) *cteDef {
+	for _, def := range s.ctes {
+		if def.name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// buildWith 构造 WITH 子句，CTE 的参数在整个语句的最前面
+func (s *SQLBuilder) buildWith(withs []*cteDef) error {
+	if len(withs) == 0 {
+		return nil
+	}
+	s.builder.WriteString(SQLWith)
+	for _, def := range withs {
+		if def.recursive != nil {
+			s.builder.WriteString(" ")
+			s.builder.WriteString(SQLRecursive)
+			break
+		}
+	}
+	s.builder.WriteString(" ")
+	for i, def := range withs {
+		if i > 0 {
+			s.Comma()
+		}
+		s.Quota(def.name)
+		s.builder.WriteString(" AS (")
+		if err := s.buildSelectQuery(def.anchor); err != nil {
+			return err
+		}
+		if def.recursive != nil {
+			s.Margin(SQLUnionAll)
+			if err := s.buildSelectQuery(def.recursive); err != nil {
+				return err
+			}
+		}
+		s.builder.WriteString(")")
+	}
+	s.builder.WriteString(" ")
+	return nil
+}
+
+// buildSelectQuery 子查询能看到外层已经定义的 CTE，
+// 这样递归部分才能引用 CTE 自身
+func (s *SQLBuilder) buildSelectQuery(q SelectQuery) error {
+	sb := q.sqlBuilder()
+	sb.outerCTEs = s.ctes
+	if err := q.build(); err != nil {
+		return err
+	}
+	s.builder.WriteString(sb.builder.String())
+	s.AddArgs(sb.args...)
+	return nil
+}
+
+// cteColumn 找到 CTE 中字段对应的列名。
+// CTE 如果指定了列，那么只能引用这些列；否则可以引用模型的任意字段
+func (s *SQLBuilder) cteColumn(ct CommonTable, name string) (string, error) {
+	def := s.findCTE(ct.name)
+	if def == nil {
+		return "", errs.NewErrUnknownCTE(ct.name)
+	}
+	m := def.model()
+	cols := def.anchor.selectedColumns()
+	if len(cols) == 0 {
+		fd, ok := m.FieldMap[name]
+		if !ok {
+			return "", errs.NewErrUnknownField(name)
+		}
+		return fd.ColName, nil
+	}
+	for _, c := range cols {
+		alias := selectableAlias(c)
+		if alias != "" && alias == name {
+			return alias, nil
+		}
+		col, ok := c.(Column)
+		if !ok || col.name != name {
+			continue
+		}
+		if alias != "" {
+			return alias, nil
+		}
+		fd, err := def.anchor.sqlBuilder().fieldOf(col)
+		if err != nil {
+			return "", err
+		}
+		return fd.ColName, nil
+	}
+	return "", errs.NewErrUnknownField(name)
+}
@@ -0,0 +1,144 @@
+package toyorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelector_With(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "with",
+			q: NewSelector[TestModel](db).
+				With("adult", NewSelector[TestModel](db).Where(Col("Age").GT(18))).
+				From(CTE("adult")).Where(Col("FirstName").EQ("Tom")),
+			wantQuery: &Query{
+				SQL: "WITH `adult` AS (SELECT * FROM `test_model` WHERE `age` > ?) " +
+					"SELECT * FROM `adult` WHERE `first_name` = ?;",
+				Args: []any{18, "Tom"},
+			},
+		},
+		{
+			name: "multiple with",
+			q: NewSelector[TestModel](db).
+				With("adult", NewSelector[TestModel](db).Where(Col("Age").GT(18))).
+				With("tom", NewSelector[TestModel](db).Select(Col("Id")).Where(Col("FirstName").EQ("Tom"))).
+				From(CTE("adult").Join(CTE("tom")).On(CTE("adult").C("Id").EQ(CTE("tom").C("Id")))),
+			wantQuery: &Query{
+				SQL: "WITH `adult` AS (SELECT * FROM `test_model` WHERE `age` > ?), " +
+					"`tom` AS (SELECT `id` FROM `test_model` WHERE `first_name` = ?) " +
+					"SELECT * FROM (`adult` JOIN `tom` ON `adult`.`id` = `tom`.`id`);",
+				Args: []any{18, "Tom"},
+			},
+		},
+		{
+			name: "recursive",
+			q: NewSelector[Category](db).
+				WithRecursive("tree",
+					NewSelector[Category](db).Where(Col("Id").EQ(1)),
+					NewSelector[Category](db).
+						Select(TableOf(&Category{}).As("c").C("Id"),
+							TableOf(&Category{}).As("c").C("ParentId"),
+							TableOf(&Category{}).As("c").C("Name")).
+						From(TableOf(&Category{}).As("c").
+							Join(CTE("tree").As("t")).
+							On(TableOf(&Category{}).As("c").C("ParentId").EQ(CTE("tree").As("t").C("Id"))))).
+				From(CTE("tree")),
+			wantQuery: &Query{
+				SQL: "WITH RECURSIVE `tree` AS (SELECT * FROM `category` WHERE `id` = ? UNION ALL " +
+					"SELECT `c`.`id`, `c`.`parent_id`, `c`.`name` FROM (`category` AS `c` JOIN `tree` AS `t` ON `c`.`parent_id` = `t`.`id`)) " +
+					"SELECT * FROM `tree`;",
+				Args: []any{1},
+			},
+		},
+		{
+			// 通过别名引用 CTE 的列
+			name: "column alias",
+			q: NewSelector[TestModel](db).
+				With("stat", NewSelector[TestModel](db).Select(Col("Age"), Count("Id").AS("cnt")).GroupBy(Col("Age"))).
+				Select(CTE("stat").C("Age"), CTE("stat").C("cnt")).
+				From(CTE("stat")),
+			wantQuery: &Query{
+				SQL: "WITH `stat` AS (SELECT `age`, COUNT(`id`) AS `cnt` FROM `test_model` GROUP BY `age`) " +
+					"SELECT `stat`.`age`, `stat`.`cnt` FROM `stat`;",
+			},
+		},
+		{
+			// CTE 里面没有选这一列
+			name: "column not in cte",
+			q: NewSelector[TestModel](db).
+				With("tom", NewSelector[TestModel](db).Select(Col("Id"))).
+				Select(CTE("tom").C("Age")).
+				From(CTE("tom")),
+			wantErr: errs.NewErrUnknownField("Age"),
+		},
+		{
+			name: "unknown field",
+			q: NewSelector[TestModel](db).
+				With("adult", NewSelector[TestModel](db)).
+				Where(CTE("adult").C("Invalid").EQ(1)).
+				From(CTE("adult")),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name:    "undefined cte",
+			q:       NewSelector[TestModel](db).From(CTE("adult")),
+			wantErr: errs.NewErrUnknownCTE("adult"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+type Category struct {
+	Id       int64
+	ParentId int64
+	Name     string
+}
+
+func TestSelector_WithRecursive_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:cte.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE category(id INTEGER PRIMARY KEY, parent_id INTEGER, name TEXT);" +
+		"INSERT INTO category VALUES (1, 0, 'root'), (2, 1, 'a'), (3, 2, 'b'), (4, 0, 'other');")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := TableOf(&Category{}).As("c")
+	res, err := NewSelector[Category](db).
+		WithRecursive("tree",
+			NewSelector[Category](db).Where(Col("Id").EQ(1)),
+			NewSelector[Category](db).
+				Select(c.C("Id"), c.C("ParentId"), c.C("Name")).
+				From(c.Join(CTE("tree").As("t")).On(c.C("ParentId").EQ(CTE("tree").As("t").C("Id"))))).
+		From(CTE("tree")).OrderBy(Asc("Id")).
+		GetMulti(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []*Category{
+		{Id: 1, ParentId: 0, Name: "root"},
+		{Id: 2, ParentId: 1, Name: "a"},
+		{Id: 3, ParentId: 2, Name: "b"},
+	}, res)
+}
@@ -3,10 +3,13 @@ package toyorm
 import (
 	"context"
 	"database/sql"
+	"reflect"
+	"time"
 
 	"go.uber.org/multierr"
 
 	"github.com/aristletl/toyorm/internal/model"
+	"github.com/aristletl/toyorm/internal/valuer"
 )
 
 type DBOption func(*DB)
@@ -45,8 +48,10 @@ func OpenDB(db *sql.DB, opts ...DBOption) (*DB, error) {
 
 	res := &DB{
 		core: core{
-			r:       r,
-			dialect: &mysqlDialect{},
+			r:          r,
+			dialect:    MySQL,
+			clock:      time.Now,
+			valCreator: valuer.NewGeneratedValue,
 		},
 		db: db,
 	}
@@ -58,11 +63,11 @@ func OpenDB(db *sql.DB, opts ...DBOption) (*DB, error) {
 }
 
 func (d *DB) Begin(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
-	tx, err := d.db.Begin()
+	tx, err := d.db.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
-	return &Tx{tx: tx}, nil
+	return &Tx{core: d.core, tx: tx}, nil
 }
 
 func (d *DB) DoTx(ctx context.Context, opts *sql.TxOptions, task func(ctx context.Context, tx *Tx) error) (err error) {
@@ -73,7 +78,8 @@ func (d *DB) DoTx(ctx context.Context, opts *sql.TxOptions, task func(ctx contex
 
 	panicked := true
 	defer func() {
-		if panicked {
+		// 业务返回 error 或者 panic 都需要回滚
+		if panicked || err != nil {
 			e := tx.RollBack()
 			err = multierr.Combine(err, e)
 		} else {
@@ -103,3 +109,27 @@ func DBWithMiddlewares(ms ...Middleware) DBOption {
 		db.ms = ms
 	}
 }
+
+// DBWithConverter 注册类型 T 的转换器，用于绑定参数和扫描结果，
+// *T 类型的字段和参数也会使用这个转换器
+func DBWithConverter[T any](c Converter) DBOption {
+	return func(db *DB) {
+		db.r.RegisterConverter(reflect.TypeOf((*T)(nil)).Elem(), c)
+	}
+}
+
+// DBWithValuer 指定访问结构体字段的方式，
+// 例如 DBWithValuer(ReflectValuer)，默认是 GeneratedValuer
+func DBWithValuer(creator ValueCreator) DBOption {
+	return func(db *DB) {
+		db.valCreator = creator
+	}
+}
+
+// DBWithClock 指定 autoCreateTime 和 autoUpdateTime 字段使用的时钟，
+// 一般用于测试
+func DBWithClock(clock func() time.Time) DBOption {
+	return func(db *DB) {
+		db.clock = clock
+	}
+}
@@ -0,0 +1,131 @@
+package toyorm
+
+import (
+	"context"
+
+	"github.com/aristletl/toyorm/internal/valuer"
+)
+
+// Deleter 模型有软删除字段的时候，默认生成 UPDATE 语句写入删除时间，
+// 并且只会删除还没有被删除的数据
+type Deleter[T any] struct {
+	SQLBuilder
+	sess       Session
+	valCreator valuer.Creator
+	val        *T
+	where      []Predicate
+
+	// unscoped 不过滤已经软删除的数据
+	unscoped bool
+	// hard 真正删除数据
+	hard bool
+}
+
+func NewDeleter[T any](sess Session) *Deleter[T] {
+	return &Deleter[T]{
+		sess: sess,
+		SQLBuilder: SQLBuilder{
+			core: sess.getCore(),
+		},
+		valCreator: sess.getCore().valCreator,
+	}
+}
+
+// Delete 指定要删除的实体，没有调用 Where 的时候按照实体的主键删除，
+// 并且会调用实体的 BeforeDelete 和 AfterDelete 钩子
+func (d *Deleter[T]) Delete(t *T) *Deleter[T] {
+	d.val = t
+	return d
+}
+
+// Where 不调用 Where 也没有调用 Delete 会删除整张表的数据
+func (d *Deleter[T]) Where(ps ...Predicate) *Deleter[T] {
+	d.where = ps
+	return d
+}
+
+// Unscoped 软删除的时候同样覆盖已经删除的数据的删除时间
+func (d *Deleter[T]) Unscoped() *Deleter[T] {
+	d.unscoped = true
+	return d
+}
+
+// HardDelete 使用 DELETE 语句真正删除数据，包括已经软删除的数据
+func (d *Deleter[T]) HardDelete() *Deleter[T] {
+	d.hard = true
+	return d
+}
+
+// Exec 软删除的时候 QueryContext.Type 是 SQLUpdate
+func (d *Deleter[T]) Exec(ctx context.Context) Result {
+	var (
+		t    T
+		vals []*T
+	)
+	m, err := d.r.Get(&t)
+	if err != nil {
+		return Result{err: err}
+	}
+	if d.val != nil {
+		vals = []*T{d.val}
+	}
+	if err = runHooks(ctx, vals, BeforeDeleteHook.BeforeDelete); err != nil {
+		return Result{err: err}
+	}
+
+	typ := SQLDelete
+	if m.SoftDelete != nil && !d.hard {
+		typ = SQLUpdate
+	}
+	res := exec(ctx, d.sess, d, typ)
+	if res.err == nil {
+		res.err = runHooks(ctx, vals, AfterDeleteHook.AfterDelete)
+	}
+	return res
+}
+
+func (d *Deleter[T]) Build() (*Query, error) {
+	var (
+		err error
+		t   T
+	)
+	d.reset()
+	d.model, err = d.r.Get(&t)
+	if err != nil {
+		return nil, err
+	}
+
+	where := d.where
+	if len(where) == 0 && d.val != nil {
+		if where, err = entityWhere(d.model, d.valCreator(d.val, d.model)); err != nil {
+			return nil, err
+		}
+	}
+	if deleted := d.model.SoftDelete; deleted != nil && !d.hard {
+		d.builder.WriteString(SQLUpdate)
+		d.Quota(d.model.TableName)
+		d.Margin(SQLSet)
+		if err = d.buildAssignment(Assign(deleted.Name, d.clock())); err != nil {
+			return nil, err
+		}
+		if !d.unscoped {
+			where = append(where[:len(where):len(where)], d.softDeletePredicates(nil, d.model)...)
+		}
+	} else {
+		d.builder.WriteString(SQLDelete)
+		d.Margin(SQLFrom)
+		d.Quota(d.model.TableName)
+	}
+
+	if len(where) != 0 {
+		d.Margin(SQLWhere)
+		if err = d.buildPredicates(where); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Query{
+		SQL:  d.string(),
+		Args: d.args,
+	}, nil
+}
@@ -0,0 +1,170 @@
+package toyorm
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleter_Build(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	db := memoryDB(t, DBWithClock(func() time.Time { return now }))
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "no where",
+			q:    NewDeleter[TestModel](db),
+			wantQuery: &Query{
+				SQL: "DELETE FROM `test_model`;",
+			},
+		},
+		{
+			name: "where",
+			q:    NewDeleter[TestModel](db).Where(Col("Id").EQ(16)),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE `id` = ?;",
+				Args: []any{16},
+			},
+		},
+		{
+			name: "in",
+			q:    NewDeleter[TestModel](db).Where(Col("Id").In(1, 2, 3)),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE `id` IN (?, ?, ?);",
+				Args: []any{1, 2, 3},
+			},
+		},
+		{
+			name:    "invalid column",
+			q:       NewDeleter[TestModel](db).Where(Col("Invalid").EQ(1)),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "entity",
+			q:    NewDeleter[TestModel](db).Delete(&TestModel{Id: 16}),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE `id` = ?;",
+				Args: []any{int64(16)},
+			},
+		},
+		{
+			name:    "entity zero primary key",
+			q:       NewDeleter[TestModel](db).Delete(&TestModel{}),
+			wantErr: errs.ErrZeroPrimaryKey,
+		},
+		{
+			name: "soft delete",
+			q:    NewDeleter[SoftModel](db).Where(Col("Id").EQ(16)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `soft_model` SET `deleted_at`=? WHERE (`id` = ?) AND (`deleted_at` IS NULL);",
+				Args: []any{now, 16},
+			},
+		},
+		{
+			name: "soft delete unscoped",
+			q:    NewDeleter[SoftModel](db).Where(Col("Id").EQ(16)).Unscoped(),
+			wantQuery: &Query{
+				SQL:  "UPDATE `soft_model` SET `deleted_at`=? WHERE `id` = ?;",
+				Args: []any{now, 16},
+			},
+		},
+		{
+			name: "hard delete",
+			q:    NewDeleter[SoftModel](db).Where(Col("Id").EQ(16)).HardDelete(),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `soft_model` WHERE `id` = ?;",
+				Args: []any{16},
+			},
+		},
+		{
+			name:    "multiple soft delete",
+			q:       NewDeleter[MultipleSoftModel](db),
+			wantErr: errs.NewErrMultipleSoftDelete("DeletedAt", "RemovedAt"),
+		},
+		{
+			name:    "invalid soft delete type",
+			q:       NewDeleter[InvalidSoftModel](db),
+			wantErr: errs.NewErrInvalidSoftDeleteType("DeletedAt"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestDeleter_SoftDelete(t *testing.T) {
+	db, err := Open("sqlite3", "file:soft_delete.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE soft_model(id INTEGER PRIMARY KEY, name TEXT, deleted_at DATETIME);" +
+		"INSERT INTO soft_model(id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c');")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	repo := NewRepository[SoftModel](db)
+	require.NoError(t, repo.DeleteByPK(ctx, 1).Err())
+	_, err = repo.FindByPK(ctx, 1)
+	assert.Equal(t, errs.ErrNoRows, err)
+
+	// 已经删除的数据不会被更新
+	res := NewUpdater[SoftModel](db).Set(Assign("Name", "x")).Exec(ctx)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	all, err := NewSelector[SoftModel](db).Unscoped().OrderBy(Asc("Id")).GetMulti(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "a", all[0].Name)
+	assert.NotNil(t, all[0].DeletedAt)
+	assert.Equal(t, "x", all[1].Name)
+	assert.Nil(t, all[1].DeletedAt)
+
+	res = NewDeleter[SoftModel](db).Where(Col("Id").EQ(1)).HardDelete().Exec(ctx)
+	affected, err = res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	cnt, err := NewSelector[SoftModel](db).Unscoped().GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Len(t, cnt, 2)
+}
+
+type SoftModel struct {
+	Id        int64
+	Name      string
+	DeletedAt *time.Time `orm:"softDelete"`
+}
+
+type SoftItem struct {
+	Id        int64
+	ModelId   int64
+	DeletedAt sql.NullTime `orm:"softDelete"`
+}
+
+type MultipleSoftModel struct {
+	Id        int64
+	DeletedAt *time.Time `orm:"softDelete"`
+	RemovedAt *time.Time `orm:"softDelete"`
+}
+
+type InvalidSoftModel struct {
+	Id        int64
+	DeletedAt time.Time `orm:"softDelete"`
+}
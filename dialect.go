@@ -1,21 +1,119 @@
 package toyorm
 
+import "github.com/aristletl/toyorm/internal/errs"
+
+var (
+	MySQL Dialect = &mysqlDialect{}
+	// SQLite 按照 3.32 之前的 999 个参数拆分批量插入，
+	// 确定数据库的版本更新的话使用 SQLiteWithMaxPlaceholders
+	SQLite Dialect = &sqliteDialect{maxPlaceholders: 999}
+)
+
+// SQLiteWithMaxPlaceholders 指定一条语句最多的参数数量，即编译 sqlite 时的 SQLITE_MAX_VARIABLE_NUMBER，
+// 3.32 之后默认是 32766
+func SQLiteWithMaxPlaceholders(n int) Dialect {
+	return &sqliteDialect{maxPlaceholders: n}
+}
+
 // Dialect 方言， 构造个性部分
 type Dialect interface {
 	// Quoter 方言中的引号不太一样
 	Quoter() byte
 	BuildOnDuplicateKey(sb *SQLBuilder, odk *Upsert) error
+	// BuildLock 构造 SELECT 末尾的行锁子句
+	BuildLock(sb *SQLBuilder, l *Lock) error
+	// BuildOrderBy 构造单个排序项，主要是处理 NULLS FIRST 和 NULLS LAST
+	BuildOrderBy(sb *SQLBuilder, o OrderBy) error
+	// SupportReturning 是否支持 INSERT ... RETURNING，
+	// 不支持的话通过 LastInsertId 获得自增 ID
+	SupportReturning() bool
+	// MaxPlaceholders 单条语句最多可以有多少个占位符
+	MaxPlaceholders() int
+	// BuildInsertIgnore 构造 INSERT 后面忽略冲突的关键字
+	BuildInsertIgnore(sb *SQLBuilder) error
+	// BuildJSONPath 构造取出 JSON 列中某个路径的值的表达式
+	BuildJSONPath(sb *SQLBuilder, j JSONPathExpr) error
+	// BuildSetOperand 构造组合查询里面带有 ORDER BY 或者 LIMIT 的子查询
+	BuildSetOperand(sb *SQLBuilder, sub string) error
 }
 
 // SQL 标准实现
 type standardSQL struct {
 }
 
+func (s standardSQL) BuildLock(b *SQLBuilder, l *Lock) error {
+	if l == nil {
+		return nil
+	}
+	b.builder.WriteString(" ")
+	b.builder.WriteString(string(l.mode))
+	if l.wait != lockWaitDefault {
+		b.builder.WriteString(" ")
+		b.builder.WriteString(string(l.wait))
+	}
+	return nil
+}
+
+func (s standardSQL) BuildInsertIgnore(b *SQLBuilder) error {
+	return errs.NewErrDialectUnsupported("SQL", "INSERT IGNORE")
+}
+
+func (s standardSQL) BuildJSONPath(b *SQLBuilder, j JSONPathExpr) error {
+	return errs.NewErrDialectUnsupported("SQL", "JSON")
+}
+
+func (s standardSQL) BuildSetOperand(b *SQLBuilder, sub string) error {
+	b.builder.WriteString("(")
+	b.builder.WriteString(sub)
+	b.builder.WriteString(")")
+	return nil
+}
+
+func (s standardSQL) SupportReturning() bool {
+	return false
+}
+
+func (s standardSQL) BuildOrderBy(b *SQLBuilder, o OrderBy) error {
+	if err := b.buildOrderByTarget(o); err != nil {
+		return err
+	}
+	if o.order != "" {
+		b.builder.WriteString(" ")
+		b.builder.WriteString(o.order)
+	}
+	if o.nulls != "" {
+		b.builder.WriteString(" NULLS ")
+		b.builder.WriteString(o.nulls)
+	}
+	return nil
+}
+
 // MySQL 方言实现
 type mysqlDialect struct {
 	standardSQL
 }
 
+// MaxPlaceholders MySQL 预编译语句的参数数量是两个字节
+func (m *mysqlDialect) MaxPlaceholders() int {
+	return 65535
+}
+
+func (m *mysqlDialect) BuildInsertIgnore(b *SQLBuilder) error {
+	b.builder.WriteString(" IGNORE")
+	return nil
+}
+
+// BuildJSONPath JSON_EXTRACT 返回的字符串带有引号，
+// 用 JSON_UNQUOTE 去掉，和 sqlite 的 json_extract 保持一致
+func (m *mysqlDialect) BuildJSONPath(b *SQLBuilder, j JSONPathExpr) error {
+	b.builder.WriteString("JSON_UNQUOTE(")
+	if err := b.buildJSONExtract("JSON_EXTRACT", j); err != nil {
+		return err
+	}
+	b.builder.WriteString(")")
+	return nil
+}
+
 func (m *mysqlDialect) Quoter() byte {
 	return '`'
 }
@@ -45,7 +143,131 @@ func (m *mysqlDialect) BuildOnDuplicateKey(b *SQLBuilder, odk *Upsert) error {
 	return nil
 }
 
+// BuildLock MySQL 5.7 不支持 FOR SHARE，
+// 所以没有 NOWAIT、SKIP LOCKED 的时候退化为 LOCK IN SHARE MODE
+func (m *mysqlDialect) BuildLock(b *SQLBuilder, l *Lock) error {
+	if l != nil && l.mode == lockForShare && l.wait == lockWaitDefault {
+		b.builder.WriteString(" LOCK IN SHARE MODE")
+		return nil
+	}
+	return m.standardSQL.BuildLock(b, l)
+}
+
+// BuildOrderBy MySQL 不支持 NULLS FIRST，用 `col` IS NULL 排序模拟，
+// NULL 在 MySQL 里面本来就是最小的，所以和默认行为一致的时候不额外处理
+func (m *mysqlDialect) BuildOrderBy(b *SQLBuilder, o OrderBy) error {
+	desc := o.order == "DESC"
+	if o.nulls == "" || (o.nulls == "FIRST") != desc {
+		o.nulls = ""
+		return m.standardSQL.BuildOrderBy(b, o)
+	}
+	if err := b.buildOrderByTarget(o); err != nil {
+		return err
+	}
+	if o.nulls == "FIRST" {
+		b.builder.WriteString(" IS NULL DESC, ")
+	} else {
+		b.builder.WriteString(" IS NULL ASC, ")
+	}
+	o.nulls = ""
+	return m.standardSQL.BuildOrderBy(b, o)
+}
+
 // sqlite 方言实现
 type sqliteDialect struct {
 	standardSQL
+	maxPlaceholders int
+}
+
+// BuildJSONPath 使用 json1 扩展的 json_extract，字符串会去掉引号
+func (s *sqliteDialect) BuildJSONPath(b *SQLBuilder, j JSONPathExpr) error {
+	return b.buildJSONExtract("json_extract", j)
+}
+
+// BuildSetOperand sqlite 不允许组合查询的子查询加括号，
+// 只能放到 FROM 里面
+func (s *sqliteDialect) BuildSetOperand(b *SQLBuilder, sub string) error {
+	b.builder.WriteString("SELECT * FROM (")
+	b.builder.WriteString(sub)
+	b.builder.WriteString(")")
+	return nil
+}
+
+func (s *sqliteDialect) Quoter() byte {
+	return '`'
+}
+
+// BuildOnDuplicateKey sqlite 使用 ON CONFLICT DO UPDATE，
+// 没有指定冲突的列时使用主键
+func (s *sqliteDialect) BuildOnDuplicateKey(b *SQLBuilder, odk *Upsert) error {
+	if odk == nil {
+		return nil
+	}
+	b.Margin("ON CONFLICT")
+	b.builder.WriteString("(")
+	if len(odk.conflictColumns) > 0 {
+		for i, c := range odk.conflictColumns {
+			if i > 0 {
+				b.Comma()
+			}
+			if err := b.buildColumn(c); err != nil {
+				return err
+			}
+		}
+	} else {
+		if len(b.model.PrimaryKeys) == 0 {
+			return errs.ErrNoPrimaryKey
+		}
+		for i, pk := range b.model.PrimaryKeys {
+			if i > 0 {
+				b.Comma()
+			}
+			b.Quota(pk.ColName)
+		}
+	}
+	b.builder.WriteString(")")
+	b.Margin("DO UPDATE SET")
+	for idx, assign := range odk.assigns {
+		if idx > 0 {
+			b.Comma()
+		}
+		switch expr := assign.(type) {
+		case Assignment:
+			if err := b.buildAssignment(expr); err != nil {
+				return err
+			}
+		case Column:
+			if err := b.buildColumn(expr.name); err != nil {
+				return err
+			}
+			b.builder.WriteString("=excluded.")
+			_ = b.buildColumn(expr.name)
+		}
+	}
+	return nil
+}
+
+// MaxPlaceholders 即 SQLITE_MAX_VARIABLE_NUMBER，3.32 之前是 999，之后是 32766
+func (s *sqliteDialect) MaxPlaceholders() int {
+	return s.maxPlaceholders
+}
+
+// BuildInsertIgnore 不使用 ON CONFLICT DO NOTHING，
+// 因为它跟在 INSERT ... SELECT 后面会有语法歧义
+func (s *sqliteDialect) BuildInsertIgnore(b *SQLBuilder) error {
+	b.builder.WriteString(" OR IGNORE")
+	return nil
+}
+
+// SupportReturning sqlite 3.35 之后支持 RETURNING
+func (s *sqliteDialect) SupportReturning() bool {
+	return true
+}
+
+// BuildLock sqlite 是库级别的锁，不支持行锁
+func (s *sqliteDialect) BuildLock(b *SQLBuilder, l *Lock) error {
+	if l != nil {
+		return errs.NewErrDialectUnsupported("sqlite", string(l.mode))
+	}
+	return nil
 }
@@ -0,0 +1,182 @@
+package toyorm
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+func TestEmbedded_Build(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	db := memoryDB(t, DBWithClock(func() time.Time { return now }))
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "insert",
+			q: NewInserter[Customer](db).Values(&Customer{
+				BaseModel: BaseModel{Id: 1},
+				Name:      "tom",
+				Home:      Address{City: "hz", Street: "xihu"},
+				Work:      Address{City: "sh"},
+			}),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `customer`(`id`, `created_at`, `name`, `home_city`, `home_street`, `work_city`, `work_street`) " +
+					"VALUES(?, ?, ?, ?, ?, ?, ?);",
+				Args: []any{int64(1), now, "tom", "hz", "xihu", "sh", ""},
+			},
+		},
+		{
+			name: "select",
+			q: NewSelector[Customer](db).Select(Col("Id"), Col("Home.City")).
+				Where(Col("Work.City").EQ("sh")),
+			wantQuery: &Query{
+				SQL:  "SELECT `id`, `home_city` FROM `customer` WHERE `work_city` = ?;",
+				Args: []any{"sh"},
+			},
+		},
+		{
+			// 外层的 Id 覆盖了嵌入的 Id
+			name: "shadowed",
+			q:    NewSelector[ShadowModel](db).Where(Col("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `shadow_model` WHERE `id` = ?;",
+				Args: []any{1},
+			},
+		},
+		{
+			// 同一层的 Id 有歧义，但是外层的 Id 覆盖了它们
+			name: "shadowed ambiguous",
+			q: NewInserter[ShadowAmbiguousModel](db).Values(&ShadowAmbiguousModel{
+				BaseModel: BaseModel{Id: 1},
+				OtherBase: OtherBase{Id: 2},
+				Id:        3,
+			}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `shadow_ambiguous_model`(`created_at`, `id`) VALUES(?, ?);",
+				Args: []any{now, int64(3)},
+			},
+		},
+		{
+			name:    "ambiguous",
+			q:       NewSelector[AmbiguousModel](db),
+			wantErr: errs.NewErrAmbiguousField("Id"),
+		},
+		{
+			name:    "duplicate column",
+			q:       NewSelector[DuplicateColumnModel](db),
+			wantErr: errs.NewErrDuplicateColumn("Home.City", "Work.City", "city"),
+		},
+		{
+			name:    "embedded pointer",
+			q:       NewSelector[EmbeddedPointerModel](db),
+			wantErr: errs.NewErrInvalidEmbeddedType("BaseModel"),
+		},
+		{
+			name:    "embedded not struct",
+			q:       NewSelector[EmbeddedNotStructModel](db),
+			wantErr: errs.NewErrInvalidEmbeddedType("Name"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestEmbedded_Valuer(t *testing.T) {
+	db, err := Open("sqlite3", "file:embedded.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE customer(id INTEGER PRIMARY KEY AUTOINCREMENT, created_at DATETIME, " +
+		"name TEXT, home_city TEXT, home_street TEXT, work_city TEXT, work_street TEXT);")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	c := &Customer{Name: "tom", Home: Address{City: "hz", Street: "xihu"}, Work: Address{City: "sh"}}
+	require.NoError(t, NewInserter[Customer](db).Values(c).Exec(ctx).Err())
+	// 自增 ID 写回嵌入的结构体
+	assert.Equal(t, int64(1), c.Id)
+
+	for name, creator := range valuerCreators {
+		t.Run(name, func(t *testing.T) {
+			s := NewSelector[Customer](db).Where(Col("Id").EQ(1))
+			s.valCreator = creator
+			got, err := s.Get(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), got.Id)
+			assert.Equal(t, c.CreatedAt.Unix(), got.CreatedAt.Unix())
+			assert.Equal(t, "tom", got.Name)
+			assert.Equal(t, c.Home, got.Home)
+			assert.Equal(t, c.Work, got.Work)
+		})
+	}
+}
+
+type BaseModel struct {
+	Id        int64
+	CreatedAt time.Time `orm:"autoCreateTime"`
+}
+
+type Address struct {
+	City   string
+	Street string
+}
+
+type Customer struct {
+	BaseModel
+	Name string
+	Home Address `orm:"embedded,prefix=home_"`
+	Work Address `orm:"embedded,prefix=work_"`
+}
+
+type ShadowModel struct {
+	BaseModel
+	Id sql.NullInt64
+}
+
+type AmbiguousModel struct {
+	BaseModel
+	OtherBase
+}
+
+type ShadowAmbiguousModel struct {
+	BaseModel
+	OtherBase
+	Id int64
+}
+
+type OtherBase struct {
+	Id int64
+}
+
+type DuplicateColumnModel struct {
+	Id   int64
+	Home Address `orm:"embedded"`
+	Work Address `orm:"embedded"`
+}
+
+type EmbeddedPointerModel struct {
+	*BaseModel
+}
+
+type EmbeddedNotStructModel struct {
+	Id   int64
+	Name string `orm:"embedded"`
+}
@@ -18,9 +18,28 @@ func (r RawExpr) Expr() {}
 
 func (r RawExpr) selectable() {}
 
+// tableAlias 让 RawExpr 可以直接作为 From 的参数，例如 Raw("`db`.`table`")
+func (r RawExpr) tableAlias() string {
+	return ""
+}
+
 func Raw(expr string, args ...any) RawExpr {
 	return RawExpr{
 		raw:  expr,
 		args: args,
 	}
 }
+
+func (r RawExpr) Asc() OrderBy {
+	return OrderBy{
+		expr:  r,
+		order: "ASC",
+	}
+}
+
+func (r RawExpr) Desc() OrderBy {
+	return OrderBy{
+		expr:  r,
+		order: "DESC",
+	}
+}
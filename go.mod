@@ -2,14 +2,16 @@ module github.com/aristletl/toyorm
 
 go 1.18
 
-require github.com/stretchr/testify v1.8.0
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/mattn/go-sqlite3 v1.14.15
+	github.com/stretchr/testify v1.8.0
+	go.uber.org/multierr v1.8.0
+)
 
 require (
-	github.com/DATA-DOG/go-sqlmock v1.5.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/mattn/go-sqlite3 v1.14.15 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
-	go.uber.org/multierr v1.8.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,55 @@
+package toyorm
+
+import "context"
+
+// 实体可以实现下面这些钩子，返回 error 会中止当前语句，
+// Exec 或者查询会返回这个 error，在 DoTx 里面使用的时候整个事务回滚。
+// 钩子按行调用，通过 InsertMap 插入的数据和只使用 Set、Where 的语句没有实体，不会调用钩子
+
+// BeforeInsertHook 在 Inserter 执行之前调用，在填充 autoCreateTime 之类的字段之前
+type BeforeInsertHook interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInsertHook 在 Inserter 执行成功并且写回自增 ID 之后调用
+type AfterInsertHook interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdateHook 在 Updater 执行之前调用，对实体的修改会被更新到数据库
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdateHook 在 Updater 执行成功之后调用
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleteHook 在 Deleter 执行之前调用，需要通过 Deleter.Delete 传入实体
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterDeleteHook 在 Deleter 执行成功之后调用
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// AfterFindHook 在 Selector 查询出来每一行之后调用
+type AfterFindHook interface {
+	AfterFind(ctx context.Context) error
+}
+
+// runHooks 对实现了钩子 H 的每一行调用 call，遇到 error 立刻返回，
+// 例如 runHooks(ctx, vals, BeforeInsertHook.BeforeInsert)
+func runHooks[H any, T any](ctx context.Context, vals []*T, call func(H, context.Context) error) error {
+	for _, v := range vals {
+		if h, ok := any(v).(H); ok {
+			if err := call(h, ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
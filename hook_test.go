@@ -0,0 +1,133 @@
+package toyorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+func TestHooks(t *testing.T) {
+	db, err := Open("sqlite3", "file:hooks.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE hook_model(id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// BeforeInsert 的修改会被插入，AfterInsert 可以拿到自增 ID
+	hookCalls = nil
+	require.NoError(t, NewInserter[HookModel](db).Values(&HookModel{Name: "tom"}).Exec(ctx).Err())
+	assert.Equal(t, []string{"BeforeInsert", "AfterInsert:1"}, hookCalls)
+
+	hookCalls = nil
+	found, err := NewSelector[HookModel](db).Where(Col("Id").EQ(1)).Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "TOM", found.Name)
+	_, err = NewSelector[HookModel](db).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AfterFind", "AfterFind"}, hookCalls)
+
+	hookCalls = nil
+	found.Name = "jerry"
+	require.NoError(t, NewUpdater[HookModel](db).Update(found).Exec(ctx).Err())
+	assert.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, hookCalls)
+	found, err = NewSelector[HookModel](db).Where(Col("Id").EQ(1)).Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "JERRY", found.Name)
+
+	// 钩子返回 error 的时候不执行语句
+	res := NewDeleter[HookModel](db).Delete(&HookModel{Id: 1, Name: "fail"}).Exec(ctx)
+	assert.Equal(t, errHook, res.Err())
+	_, err = NewSelector[HookModel](db).Where(Col("Id").EQ(1)).Get(ctx)
+	require.NoError(t, err)
+
+	// 在事务里面后面的钩子返回 error，整个事务回滚
+	err = db.DoTx(ctx, nil, func(ctx context.Context, tx *Tx) error {
+		if err := NewInserter[HookModel](tx).Values(&HookModel{Name: "a"}).Exec(ctx).Err(); err != nil {
+			return err
+		}
+		return NewInserter[HookModel](tx).Values(&HookModel{Name: "fail"}).Exec(ctx).Err()
+	})
+	assert.Equal(t, errHook, err)
+	all, err := NewSelector[HookModel](db).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	// AfterFind 返回 error 的时候不返回实体
+	require.NoError(t, NewInserter[HookModel](db).Values(&HookModel{Name: "broken"}).Exec(ctx).Err())
+	found, err = NewSelector[HookModel](db).Where(Col("Name").EQ("BROKEN")).Get(ctx)
+	assert.Equal(t, errHook, err)
+	assert.Nil(t, found)
+	all, err = NewSelector[HookModel](db).GetMulti(ctx)
+	assert.Equal(t, errHook, err)
+	assert.Nil(t, all)
+
+	hookCalls = nil
+	require.NoError(t, NewDeleter[HookModel](db).Delete(&HookModel{Id: 1}).Exec(ctx).Err())
+	assert.Equal(t, []string{"BeforeDelete", "AfterDelete"}, hookCalls)
+	_, err = NewSelector[HookModel](db).Where(Col("Id").EQ(1)).Get(ctx)
+	assert.Equal(t, errs.ErrNoRows, err)
+}
+
+// hookCalls 记录钩子的调用，模型的所有字段都会被当成列，所以不能放在模型里面
+var hookCalls []string
+
+var errHook = errors.New("hook error")
+
+type HookModel struct {
+	Id   int64
+	Name string
+}
+
+func (h *HookModel) BeforeInsert(ctx context.Context) error {
+	if h.Name == "fail" {
+		return errHook
+	}
+	hookCalls = append(hookCalls, "BeforeInsert")
+	h.Name = strings.ToUpper(h.Name)
+	return nil
+}
+
+func (h *HookModel) AfterInsert(ctx context.Context) error {
+	hookCalls = append(hookCalls, fmt.Sprintf("AfterInsert:%d", h.Id))
+	return nil
+}
+
+func (h *HookModel) BeforeUpdate(ctx context.Context) error {
+	hookCalls = append(hookCalls, "BeforeUpdate")
+	h.Name = strings.ToUpper(h.Name)
+	return nil
+}
+
+func (h *HookModel) AfterUpdate(ctx context.Context) error {
+	hookCalls = append(hookCalls, "AfterUpdate")
+	return nil
+}
+
+func (h *HookModel) BeforeDelete(ctx context.Context) error {
+	if h.Name == "fail" {
+		return errHook
+	}
+	hookCalls = append(hookCalls, "BeforeDelete")
+	return nil
+}
+
+func (h *HookModel) AfterDelete(ctx context.Context) error {
+	hookCalls = append(hookCalls, "AfterDelete")
+	return nil
+}
+
+func (h *HookModel) AfterFind(ctx context.Context) error {
+	if h.Name == "BROKEN" {
+		return errHook
+	}
+	hookCalls = append(hookCalls, "AfterFind")
+	return nil
+}
@@ -2,6 +2,13 @@ package toyorm
 
 import (
 	"context"
+	"database/sql"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/multierr"
 
 	"github.com/aristletl/toyorm/internal/errs"
 	"github.com/aristletl/toyorm/internal/model"
@@ -15,6 +22,25 @@ type Inserter[T any] struct {
 	values      []*T
 	columns     []string
 	onDuplicate *Upsert
+
+	// backfill 插入的列里面没有自增列，执行之后需要把自增 ID 写回
+	backfill bool
+	// returning 通过 RETURNING 获得自增 ID
+	returning bool
+
+	chunked   bool
+	chunkSize int
+
+	// source INSERT ... SELECT 的数据来源
+	source SelectQuery
+	ignore bool
+
+	// maps 通过 InsertMap 传入的行，key 是字段名
+	maps []map[string]any
+	// skipZero 跳过零值字段，让数据库的默认值生效
+	skipZero bool
+	// now Exec 写回结构体的时间，拆分出来的每一条语句都使用这个时间
+	now time.Time
 }
 
 func NewInserter[T any](sess Session) *Inserter[T] {
@@ -24,50 +50,517 @@ func NewInserter[T any](sess Session) *Inserter[T] {
 		SQLBuilder: SQLBuilder{
 			core: c,
 		},
-		valCreator: valuer.NewUnsafeValue,
+		valCreator: c.valCreator,
 	}
 }
 
+// Chunk 把 Values 拆成每 size 行一批，在同一个事务里面依次执行，
+// 避免占位符超过数据库的限制。size <= 0 的时候根据方言的占位符上限计算
+func (i *Inserter[T]) Chunk(size int) *Inserter[T] {
+	i.chunked = true
+	i.chunkSize = size
+	return i
+}
+
+// Exec 执行之后会把自增 ID 和自动填充的时间写回 Values 传入的结构体，InsertMap 传入的 map 不会修改。
+// 调用了 Chunk 或者各行插入的列不一致的时候，会拆成多条语句在同一个事务里面执行，
+// 返回的 RowsAffected 是所有语句的总和，LastInsertId 是最后一条语句的。
+// Values 传入的结构体会调用 BeforeInsert 和 AfterInsert 钩子
 func (i *Inserter[T]) Exec(ctx context.Context) Result {
-	query, err := i.Build()
+	var vals []*T
+	if i.source == nil {
+		vals = i.values
+	}
+	if err := runHooks(ctx, vals, BeforeInsertHook.BeforeInsert); err != nil {
+		return Result{err: err}
+	}
+	res := i.exec(ctx)
+	if res.err == nil {
+		res.err = runHooks(ctx, vals, AfterInsertHook.AfterInsert)
+	}
+	return res
+}
+
+func (i *Inserter[T]) exec(ctx context.Context) Result {
+	if err := i.fillTimestamps(); err != nil {
+		return Result{err: err}
+	}
+	stmts, err := i.statements()
+	if err != nil {
+		return Result{err: err}
+	}
+	if stmts == nil {
+		return i.execOnce(ctx)
+	}
+
+	if tx, ok := i.sess.(*Tx); ok {
+		return execStatements(ctx, tx, stmts)
+	}
+	db, ok := i.sess.(*DB)
+	if !ok {
+		return execStatements(ctx, i.sess, stmts)
+	}
+	var res Result
+	err = db.DoTx(ctx, nil, func(ctx context.Context, tx *Tx) error {
+		res = execStatements(ctx, tx, stmts)
+		return res.err
+	})
+	if err != nil {
+		return Result{err: err}
+	}
+	return res
+}
+
+func execStatements[T any](ctx context.Context, sess Session, stmts []*Inserter[T]) Result {
+	var total rowsResult
+	for _, stmt := range stmts {
+		stmt.sess = sess
+		stmt.core = sess.getCore()
+		res := stmt.execOnce(ctx)
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return Result{err: err}
+		}
+		total.rowsAffected += affected
+		if total.lastInsertId, err = res.LastInsertId(); err != nil {
+			return Result{err: err}
+		}
+	}
+	return Result{res: total}
+}
+
+// statements 把要插入的行拆成多条语句：
+// 先按照插入的列分组，再按照 Chunk 分批。只需要一条语句的时候返回 nil
+func (i *Inserter[T]) statements() ([]*Inserter[T], error) {
+	if i.source != nil {
+		return nil, nil
+	}
+	groups := []*Inserter[T]{i}
+	if i.skipZero || len(i.maps) != 0 {
+		var err error
+		if groups, err = i.groupByColumns(); err != nil {
+			return nil, err
+		}
+	}
+	if !i.chunked {
+		if len(groups) == 1 {
+			return nil, nil
+		}
+		return groups, nil
+	}
+
+	stmts := make([]*Inserter[T], 0, len(groups))
+	for _, g := range groups {
+		size, err := g.rowsPerChunk()
+		if err != nil {
+			return nil, err
+		}
+		for start := 0; start < len(g.values); start += size {
+			end := start + size
+			if end > len(g.values) {
+				end = len(g.values)
+			}
+			stmts = append(stmts, g.sub(g.columns, g.values[start:end], nil))
+		}
+		for start := 0; start < len(g.maps); start += size {
+			end := start + size
+			if end > len(g.maps) {
+				end = len(g.maps)
+			}
+			stmts = append(stmts, g.sub(g.columns, nil, g.maps[start:end]))
+		}
+	}
+	if len(stmts) == 1 {
+		return nil, nil
+	}
+	return stmts, nil
+}
+
+// sub 复制 i 的配置，插入指定的列和行
+func (i *Inserter[T]) sub(cols []string, vals []*T, maps []map[string]any) *Inserter[T] {
+	return &Inserter[T]{
+		sess: i.sess,
+		SQLBuilder: SQLBuilder{
+			core: i.core,
+		},
+		valCreator:  i.valCreator,
+		values:      vals,
+		maps:        maps,
+		columns:     cols,
+		onDuplicate: i.onDuplicate,
+		ignore:      i.ignore,
+		now:         i.now,
+	}
+}
+
+// groupByColumns 计算每一行要插入的列，列相同的行放到同一条语句里面。
+// 分组的顺序是每组第一行出现的顺序，组内保持原来的顺序
+func (i *Inserter[T]) groupByColumns() ([]*Inserter[T], error) {
+	var (
+		err error
+		t   T
+	)
+	i.model, err = i.r.Get(&t)
+	if err != nil {
+		return nil, err
+	}
+	candidates := i.model.Columns
+	if len(i.columns) != 0 {
+		candidates = make([]*model.Field, 0, len(i.columns))
+		for _, name := range i.columns {
+			fd, ok := i.model.FieldMap[name]
+			if !ok {
+				return nil, errs.NewErrUnknownField(name)
+			}
+			candidates = append(candidates, fd)
+		}
+	}
+
+	groups := make([]*Inserter[T], 0, 1)
+	index := make(map[string]*Inserter[T], 1)
+	group := func(key string, cols []string) *Inserter[T] {
+		g, ok := index[key]
+		if !ok {
+			g = i.sub(cols, nil, nil)
+			index[key] = g
+			groups = append(groups, g)
+		}
+		return g
+	}
+
+	for _, v := range i.values {
+		val := i.valCreator(v, i.model)
+		cols := make([]string, 0, len(candidates))
+		for _, fd := range candidates {
+			fdVal, err := val.Field(fd.Index)
+			if err != nil {
+				return nil, err
+			}
+			if i.skipZero && !isAutoTime(fd) && isZero(fd, fdVal) {
+				continue
+			}
+			cols = append(cols, fd.Name)
+		}
+		g := group("v:"+strings.Join(cols, ","), cols)
+		g.values = append(g.values, v)
+	}
+
+	for _, m := range i.maps {
+		for name := range m {
+			if _, ok := i.model.FieldMap[name]; !ok {
+				return nil, errs.NewErrUnknownField(name)
+			}
+		}
+		cols := make([]string, 0, len(m))
+		for _, fd := range candidates {
+			// 零值的时间字段会补上当前时间
+			if isAutoTime(fd) {
+				cols = append(cols, fd.Name)
+				continue
+			}
+			fdVal, ok := m[fd.Name]
+			if !ok || i.skipZero && isZero(fd, fdVal) {
+				continue
+			}
+			cols = append(cols, fd.Name)
+		}
+		g := group("m:"+strings.Join(cols, ","), cols)
+		g.maps = append(g.maps, m)
+	}
+	return groups, nil
+}
+
+// fillTimestamps 在 Exec 的时候把当前时间写回结构体零值的 autoCreateTime 和 autoUpdateTime 字段，
+// upsert 的 autoUpdateTime 字段总是写回。map 是调用者的数据，不会修改
+func (i *Inserter[T]) fillTimestamps() error {
+	if i.source != nil {
+		return nil
+	}
+	var t T
+	m, err := i.r.Get(&t)
+	if err != nil {
+		return err
+	}
+	i.now = i.clock()
+	for _, fd := range m.Columns {
+		if !isAutoTime(fd) {
+			continue
+		}
+		for _, v := range i.values {
+			val := i.valCreator(v, m)
+			cur, err := val.Field(fd.Index)
+			if err != nil {
+				return err
+			}
+			ts, ok := i.autoTimeArg(fd, cur, i.now)
+			if !ok {
+				continue
+			}
+			if err = val.SetField(fd.Index, ts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// autoTimeArg 计算时间字段插入的值，ok 为 false 的时候使用 cur。
+// 零值的字段使用当前时间，已经设置了的值保持不变；
+// upsert 不管插入还是更新都是这次写入的数据，所以 autoUpdateTime 字段总是使用当前时间
+func (i *Inserter[T]) autoTimeArg(fd *model.Field, cur any, now time.Time) (any, bool) {
+	if !isAutoTime(fd) {
+		return nil, false
+	}
+	if isZero(fd, cur) || fd.AutoUpdateTime && i.onDuplicate != nil {
+		return autoTimeValue(fd, now), true
+	}
+	return nil, false
+}
+
+// currentTime Exec 的时候和写回结构体的时间保持一致，单独调用 Build 的时候使用当前时间
+func (i *Inserter[T]) currentTime() time.Time {
+	if i.now.IsZero() {
+		return i.clock()
+	}
+	return i.now
+}
+
+// upsert 冲突的时候更新 autoUpdateTime 字段：
+// Col 的形式使用的是插入的值，这里替换成当前时间，没有更新的 autoUpdateTime 字段补上当前时间，
+// 通过 Assign 指定的值由用户自己控制
+func (i *Inserter[T]) upsert(now time.Time) *Upsert {
+	if i.onDuplicate == nil {
+		return nil
+	}
+	assigns := make([]Assignable, 0, len(i.onDuplicate.assigns)+1)
+	assigned := make(map[string]struct{}, len(i.onDuplicate.assigns))
+	for _, assign := range i.onDuplicate.assigns {
+		switch expr := assign.(type) {
+		case Column:
+			assigned[expr.name] = struct{}{}
+			if fd, ok := i.model.FieldMap[expr.name]; ok && fd.AutoUpdateTime {
+				assign = Assign(fd.Name, autoTimeValue(fd, now))
+			}
+		case Assignment:
+			assigned[expr.column] = struct{}{}
+		}
+		assigns = append(assigns, assign)
+	}
+	for _, fd := range i.model.Columns {
+		if _, ok := assigned[fd.Name]; !ok && fd.AutoUpdateTime {
+			assigns = append(assigns, Assign(fd.Name, autoTimeValue(fd, now)))
+		}
+	}
+	return &Upsert{
+		assigns:         assigns,
+		conflictColumns: i.onDuplicate.conflictColumns,
+	}
+}
+
+// mapValue map 里面字段的值，零值或者没有的时间字段使用 now
+func (i *Inserter[T]) mapValue(m map[string]any, fd *model.Field, now time.Time) any {
+	v := m[fd.Name]
+	if ts, ok := i.autoTimeArg(fd, v, now); ok {
+		return ts
+	}
+	return v
+}
+
+func isAutoTime(fd *model.Field) bool {
+	return fd.AutoCreateTime || fd.AutoUpdateTime
+}
+
+// isZero 判断字段的值是不是零值。
+// 只有指针实现了 driver.Valuer 的类型，Value.Field 返回的是字段的地址，这里要判断指向的值
+func isZero(fd *model.Field, val any) bool {
+	if val == nil {
+		return true
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Pointer && fd.Type.Kind() != reflect.Pointer && rv.Type().Elem() == fd.Type {
+		return rv.IsNil() || rv.Elem().IsZero()
+	}
+	return rv.IsZero()
+}
+
+// rowsPerChunk 每一批的行数，ON DUPLICATE KEY UPDATE 的参数也要算进去
+func (i *Inserter[T]) rowsPerChunk() (int, error) {
+	if len(i.values) == 0 && len(i.maps) == 0 {
+		return 0, errs.ErrInsertZeroRow
+	}
+	if i.chunkSize > 0 {
+		return i.chunkSize, nil
+	}
+	var (
+		err error
+		t   T
+	)
+	i.model, err = i.r.Get(&t)
 	if err != nil {
-		return Result{res: nil,
-			err: err,
+		return 0, err
+	}
+	fields, err := i.fields(i.columns)
+	if err != nil {
+		return 0, err
+	}
+	limit := i.dialect.MaxPlaceholders()
+	if odk := i.upsert(time.Time{}); odk != nil {
+		limit -= len(odk.assigns)
+	}
+	size := limit
+	if len(fields) > 0 {
+		size = limit / len(fields)
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size, nil
+}
+
+func (i *Inserter[T]) execOnce(ctx context.Context) Result {
+	var root Handler = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		q, err := qc.Builder.Build()
+		if err != nil {
+			return &QueryResult{Err: err}
+		}
+		if i.returning {
+			return i.execReturning(ctx, q)
+		}
+		res, err := i.sess.execContext(ctx, q.SQL, q.Args...)
+		if err == nil && i.backfill {
+			err = i.backfillLastInsertId(res)
+		}
+		return &QueryResult{
+			Result: res,
+			Err:    err,
+		}
+	}
+	return execHandler(ctx, i.sess, i, SQLInsert, root)
+}
+
+// backfillLastInsertId MySQL 批量插入的时候 LastInsertId 是第一行的 ID，
+// 后面的行的 ID 是连续的
+func (i *Inserter[T]) backfillLastInsertId(res sql.Result) error {
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	autoInc := i.model.AutoIncrement
+	for k, v := range i.values {
+		if err = i.valCreator(v, i.model).SetField(autoInc.Index, id+int64(k)); err != nil {
+			return err
 		}
 	}
-	res, err := i.sess.execContext(ctx, query.SQL, query.Args...)
-	return Result{
-		res: res,
-		err: err,
+	return nil
+}
+
+// execReturning 回写 RETURNING 返回的 ID。
+// sqlite 不保证 RETURNING 返回的行和插入的行顺序一致，但是同一条语句里面自增的 ID 是按照插入的顺序分配的，
+// 所以把 ID 从小到大排序之后再按顺序回写，和 MySQL 依赖 ID 连续是同一个前提
+func (i *Inserter[T]) execReturning(ctx context.Context, q *Query) *QueryResult {
+	rows, err := i.sess.queryContext(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return &QueryResult{Err: err}
 	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make([]int64, 0, len(i.values))
+	for rows.Next() {
+		if len(ids) >= len(i.values) {
+			return &QueryResult{Err: errs.ErrTooManyReturnedRows}
+		}
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return &QueryResult{Err: err}
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return &QueryResult{Err: err}
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+
+	autoInc := i.model.AutoIncrement
+	var res rowsResult
+	for k, id := range ids {
+		if err = i.valCreator(i.values[k], i.model).SetField(autoInc.Index, id); err != nil {
+			return &QueryResult{Err: err}
+		}
+		res.rowsAffected++
+		res.lastInsertId = id
+	}
+	return &QueryResult{Result: res}
 }
 
 func (i *Inserter[T]) Build() (*Query, error) {
-	if len(i.values) == 0 {
+	if i.source == nil && len(i.values) == 0 && len(i.maps) == 0 {
 		return nil, errs.ErrInsertZeroRow
 	}
-	var err error
-	i.model, err = i.r.Get(i.values[0])
+	var (
+		err error
+		t   T
+	)
+	i.reset()
+	i.model, err = i.r.Get(&t)
 	if err != nil {
 		return nil, err
 	}
 
+	now := i.currentTime()
+
 	i.builder.WriteString(SQLInsert)
+	if i.ignore {
+		if err = i.dialect.BuildInsertIgnore(&i.SQLBuilder); err != nil {
+			return nil, err
+		}
+	}
 	i.Margin(SQLInto)
 	i.Quota(i.model.TableName)
 
-	if err = i.buildColumns(); err != nil {
+	cols := i.columns
+	if i.source == nil && (i.skipZero || len(i.maps) != 0) {
+		groups, err := i.groupByColumns()
+		if err != nil {
+			return nil, err
+		}
+		if len(groups) > 1 {
+			return nil, errs.ErrInsertMultipleStatements
+		}
+		cols = groups[0].columns
+	}
+	fields, err := i.fields(cols)
+	if err != nil {
+		return nil, err
+	}
+	// INSERT INTO `t`() VALUES() 不是所有数据库都支持
+	if len(fields) == 0 {
+		return nil, errs.ErrInsertNoColumns
+	}
+	if err = i.validate(fields, now); err != nil {
 		return nil, err
 	}
 
-	if err = i.buildValues(); err != nil {
+	i.buildColumns(fields)
+
+	if i.source != nil {
+		err = i.buildSource(fields)
+	} else {
+		err = i.buildValues(fields, now)
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	if err = i.dialect.BuildOnDuplicateKey(&i.SQLBuilder, i.onDuplicate); err != nil {
+	if err = i.dialect.BuildOnDuplicateKey(&i.SQLBuilder, i.upsert(now)); err != nil {
 		return nil, err
 	}
 
+	i.returning = i.backfill && i.dialect.SupportReturning()
+	if i.returning {
+		i.builder.WriteString(" RETURNING ")
+		i.Quota(i.model.AutoIncrement.ColName)
+	}
+
 	return &Query{
 		SQL:  i.string(),
 		Args: i.args,
@@ -85,47 +578,138 @@ func (i *Inserter[T]) Values(vals ...*T) *Inserter[T] {
 	return i
 }
 
+// InsertMap 使用 map 作为插入的数据，key 是字段名，必须在模型里面定义。
+// 每一行只插入 map 里面有的字段，字段不同的行会拆成多条语句执行
+func (i *Inserter[T]) InsertMap(vals ...map[string]any) *Inserter[T] {
+	i.maps = vals
+	return i
+}
+
+// SkipZero 不插入零值字段，从而使用数据库的默认值。
+// 每一行插入的列可能不一样，列不同的行会拆成多条语句执行
+func (i *Inserter[T]) SkipZero() *Inserter[T] {
+	i.skipZero = true
+	return i
+}
+
+// Select 使用查询的结果作为插入的数据，即 INSERT INTO ... SELECT，
+// 查询的列数必须和插入的列数一致，此时 Values 会被忽略
+func (i *Inserter[T]) Select(q SelectQuery) *Inserter[T] {
+	i.source = q
+	return i
+}
+
+// Ignore 忽略主键或者唯一索引冲突的行，
+// MySQL 是 INSERT IGNORE，sqlite 是 INSERT OR IGNORE
+func (i *Inserter[T]) Ignore() *Inserter[T] {
+	i.ignore = true
+	return i
+}
+
 func (i *Inserter[T]) Upsert() *UpsertBuilder[T] {
 	return &UpsertBuilder[T]{
 		i: i,
 	}
 }
 
-func (i *Inserter[T]) buildColumns() error {
-	i.builder.WriteString("(")
-	defer i.builder.WriteString(")")
-	if len(i.columns) == 0 {
-		for idx, c := range i.model.Columns {
-			if idx > 0 {
-				i.Comma()
-			}
-			i.Quota(c.ColName)
-		}
-	} else {
-		for idx, c := range i.columns {
-			if idx > 0 {
-				i.Comma()
-			}
-			if err := i.buildColumn(c); err != nil {
-				return err
-			}
-		}
+// fields 计算需要插入的列。
+// 没有指定列的时候，如果所有行的自增列都是零值，就不插入自增列，由数据库生成。
+// INSERT ... SELECT 和 Ignore 无法对应插入的行和生成的 ID，map 没有地方写回，所以都不回写
+func (i *Inserter[T]) fields(cols []string) ([]*model.Field, error) {
+	fields, err := i.insertFields(cols)
+	if i.source != nil || i.ignore || len(i.values) == 0 {
+		i.backfill = false
 	}
-	return nil
+	return fields, err
 }
 
-func (i *Inserter[T]) buildValues() error {
-	fields := i.model.Columns
-	if len(i.columns) != 0 {
-		fields = make([]*model.Field, 0, len(i.columns))
-		for _, colName := range i.columns {
+func (i *Inserter[T]) insertFields(cols []string) ([]*model.Field, error) {
+	autoInc := i.model.AutoIncrement
+	if cols != nil {
+		fields := make([]*model.Field, 0, len(cols))
+		omitted := autoInc != nil
+		for _, colName := range cols {
 			fd, ok := i.model.FieldMap[colName]
 			if !ok {
-				return errs.NewErrUnknownField(colName)
+				return nil, errs.NewErrUnknownField(colName)
 			}
+			if fd == autoInc {
+				omitted = false
+			}
+			fields = append(fields, fd)
+		}
+		i.backfill = omitted && i.onDuplicate == nil
+		return fields, nil
+	}
+
+	i.backfill = false
+	if autoInc == nil || i.onDuplicate != nil || i.source != nil {
+		return i.model.Columns, nil
+	}
+	for _, v := range i.values {
+		id, err := i.valCreator(v, i.model).Field(autoInc.Index)
+		if err != nil {
+			return nil, err
+		}
+		if !isZero(autoInc, id) {
+			return i.model.Columns, nil
+		}
+	}
+	i.backfill = true
+	fields := make([]*model.Field, 0, len(i.model.Columns)-1)
+	for _, fd := range i.model.Columns {
+		if fd != autoInc {
 			fields = append(fields, fd)
 		}
 	}
+	return fields, nil
+}
+
+// validate 校验要插入的字段，结构体还会调用 Validator
+func (i *Inserter[T]) validate(fields []*model.Field, now time.Time) error {
+	if i.source != nil {
+		return nil
+	}
+	var err error
+	for _, v := range i.values {
+		err = multierr.Append(err, validateEntity(v, i.valCreator(v, i.model), fields))
+	}
+	for _, m := range i.maps {
+		for _, fd := range fields {
+			err = multierr.Append(err, validateField(fd, i.mapValue(m, fd, now)))
+		}
+	}
+	return err
+}
+
+func (i *Inserter[T]) buildColumns(fields []*model.Field) {
+	i.builder.WriteString("(")
+	for idx, fd := range fields {
+		if idx > 0 {
+			i.Comma()
+		}
+		i.Quota(fd.ColName)
+	}
+	i.builder.WriteString(")")
+}
+
+func (i *Inserter[T]) buildSource(fields []*model.Field) error {
+	i.builder.WriteString(" ")
+	if err := i.buildSelectQuery(i.source); err != nil {
+		return err
+	}
+	cnt := len(i.source.selectedColumns())
+	if cnt == 0 {
+		cnt = len(i.source.sqlBuilder().model.Columns)
+	}
+	if cnt != len(fields) {
+		return errs.ErrInsertColumnsMismatch
+	}
+	return nil
+}
+
+// buildValues 零值的时间字段使用 now，不会写入调用者的结构体和 map
+func (i *Inserter[T]) buildValues(fields []*model.Field, now time.Time) error {
 	i.builder.WriteString(" VALUES")
 	for j := 0; j < len(i.values); j++ {
 		if j > 0 {
@@ -142,7 +726,28 @@ func (i *Inserter[T]) buildValues() error {
 			if err != nil {
 				return err
 			}
-			i.AddArgs(fdVal)
+			if ts, ok := i.autoTimeArg(meta, fdVal, now); ok {
+				fdVal = ts
+			}
+			if err = i.addArg(fdVal); err != nil {
+				return err
+			}
+		}
+		i.builder.WriteString(")")
+	}
+	for j, m := range i.maps {
+		if j > 0 || len(i.values) > 0 {
+			i.Comma()
+		}
+		i.builder.WriteString("(")
+		for k, meta := range fields {
+			if k > 0 {
+				i.Comma()
+			}
+			i.builder.WriteString("?")
+			if err := i.addArg(i.mapValue(m, meta, now)); err != nil {
+				return err
+			}
 		}
 		i.builder.WriteString(")")
 	}
@@ -150,14 +755,26 @@ func (i *Inserter[T]) buildValues() error {
 }
 
 type UpsertBuilder[T any] struct {
-	i *Inserter[T]
+	i               *Inserter[T]
+	conflictColumns []string
+}
+
+// ConflictColumns 指定冲突的字段，只有 sqlite 这类 ON CONFLICT 语法需要，
+// 默认使用主键
+func (u *UpsertBuilder[T]) ConflictColumns(fields ...string) *UpsertBuilder[T] {
+	u.conflictColumns = fields
+	return u
 }
 
 func (u *UpsertBuilder[T]) Update(assigns ...Assignable) *Inserter[T] {
-	u.i.onDuplicate = &Upsert{assigns: assigns}
+	u.i.onDuplicate = &Upsert{
+		assigns:         assigns,
+		conflictColumns: u.conflictColumns,
+	}
 	return u.i
 }
 
 type Upsert struct {
-	assigns []Assignable
+	assigns         []Assignable
+	conflictColumns []string
 }
@@ -1,8 +1,14 @@
 package toyorm
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/aristletl/toyorm/internal/errs"
 
@@ -137,6 +143,533 @@ func TestInserter_Build(t *testing.T) {
 					int64(2), "Da", int8(19), &sql.NullString{String: "Ming", Valid: true}},
 			},
 		},
+		{
+			name: "composite primary key upsert",
+			q: NewInserter[OrderItem](db).Values(&OrderItem{OrderId: 1, ItemId: 2, Qty: 3}).
+				Upsert().Update(Col("Qty")),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `order_item`(`order_id`, `item_id`, `qty`) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE `qty`=VALUES(`qty`);",
+				Args: []any{int64(1), int64(2), 3},
+			},
+		},
+		{
+			name: "sqlite upsert",
+			q: NewInserter[OrderItem](memoryDB(t, DBWithDialect(SQLite))).
+				Values(&OrderItem{OrderId: 1, ItemId: 2, Qty: 3}).
+				Upsert().Update(Col("Qty"), Assign("ItemId", 3)),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `order_item`(`order_id`, `item_id`, `qty`) VALUES(?, ?, ?) " +
+					"ON CONFLICT (`order_id`, `item_id`) DO UPDATE SET `qty`=excluded.`qty`, `item_id`=?;",
+				Args: []any{int64(1), int64(2), 3, 3},
+			},
+		},
+		{
+			name: "sqlite upsert conflict columns",
+			q: NewInserter[OrderItem](memoryDB(t, DBWithDialect(SQLite))).
+				Values(&OrderItem{OrderId: 1, ItemId: 2, Qty: 3}).
+				Upsert().ConflictColumns("OrderId").Update(Col("Qty")),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `order_item`(`order_id`, `item_id`, `qty`) VALUES(?, ?, ?) " +
+					"ON CONFLICT (`order_id`) DO UPDATE SET `qty`=excluded.`qty`;",
+				Args: []any{int64(1), int64(2), 3},
+			},
+		},
+		{
+			// 自增列是零值，交给数据库生成
+			name: "omit zero auto increment",
+			q: NewInserter[TestModel](db).Values(
+				&TestModel{FirstName: "Deng", Age: 18},
+				&TestModel{FirstName: "Da", Age: 19}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model`(`first_name`, `age`, `last_name`) VALUES(?, ?, ?), (?, ?, ?);",
+				Args: []any{"Deng", int8(18), nil, "Da", int8(19), nil},
+			},
+		},
+		{
+			// 只要有一行指定了 ID，就要插入自增列
+			name: "partial zero auto increment",
+			q: NewInserter[TestModel](db).Values(
+				&TestModel{FirstName: "Deng"},
+				&TestModel{Id: 2, FirstName: "Da"}),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `test_model`(`id`, `first_name`, `age`, `last_name`) VALUES(?, ?, ?, ?), (?, ?, ?, ?);",
+				Args: []any{int64(0), "Deng", int8(0), nil,
+					int64(2), "Da", int8(0), nil},
+			},
+		},
+		{
+			name: "sqlite returning",
+			q: NewInserter[TestModel](memoryDB(t, DBWithDialect(SQLite))).Values(
+				&TestModel{FirstName: "Deng", Age: 18}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model`(`first_name`, `age`, `last_name`) VALUES(?, ?, ?) RETURNING `id`;",
+				Args: []any{"Deng", int8(18), nil},
+			},
+		},
+		{
+			name: "insert select",
+			q: NewInserter[TestModelArchive](db).
+				Select(NewSelector[TestModel](db).Where(Col("Age").GT(18))),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model_archive`(`id`, `first_name`, `age`, `last_name`) SELECT * FROM `test_model` WHERE `age` > ?;",
+				Args: []any{18},
+			},
+		},
+		{
+			name: "insert select columns",
+			q: NewInserter[TestModelArchive](db).Columns("Id", "FirstName").
+				Select(NewSelector[TestModel](db).Select(Col("Id"), Col("FirstName"))),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `test_model_archive`(`id`, `first_name`) SELECT `id`, `first_name` FROM `test_model`;",
+			},
+		},
+		{
+			name: "insert select columns mismatch",
+			q: NewInserter[TestModelArchive](db).Columns("Id").
+				Select(NewSelector[TestModel](db)),
+			wantErr: errs.ErrInsertColumnsMismatch,
+		},
+		{
+			name: "insert select invalid column",
+			q: NewInserter[TestModelArchive](db).
+				Select(NewSelector[TestModel](db).Where(Col("Invalid").EQ(1))),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "ignore",
+			q:    NewInserter[TestModel](db).Values(&TestModel{Id: 1, FirstName: "Deng"}).Ignore(),
+			wantQuery: &Query{
+				SQL:  "INSERT IGNORE INTO `test_model`(`id`, `first_name`, `age`, `last_name`) VALUES(?, ?, ?, ?);",
+				Args: []any{int64(1), "Deng", int8(0), nil},
+			},
+		},
+		{
+			// 忽略冲突的时候不回写 ID，也就不需要 RETURNING
+			name: "sqlite ignore",
+			q: NewInserter[TestModel](memoryDB(t, DBWithDialect(SQLite))).
+				Values(&TestModel{FirstName: "Deng"}).Ignore(),
+			wantQuery: &Query{
+				SQL:  "INSERT OR IGNORE INTO `test_model`(`first_name`, `age`, `last_name`) VALUES(?, ?, ?);",
+				Args: []any{"Deng", int8(0), nil},
+			},
+		},
+		{
+			name: "ignore insert select",
+			q: NewInserter[TestModelArchive](db).Ignore().
+				Select(NewSelector[TestModel](db)),
+			wantQuery: &Query{
+				SQL: "INSERT IGNORE INTO `test_model_archive`(`id`, `first_name`, `age`, `last_name`) SELECT * FROM `test_model`;",
+			},
+		},
+		{
+			name: "skip zero",
+			q: NewInserter[TestModel](db).SkipZero().
+				Values(&TestModel{FirstName: "Deng"}, &TestModel{FirstName: "Da"}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model`(`first_name`) VALUES(?), (?);",
+				Args: []any{"Deng", "Da"},
+			},
+		},
+		{
+			name: "skip zero with columns",
+			q: NewInserter[TestModel](db).SkipZero().Columns("FirstName", "Age").
+				Values(&TestModel{FirstName: "Deng", Age: 18, LastName: &sql.NullString{}}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model`(`first_name`, `age`) VALUES(?, ?);",
+				Args: []any{"Deng", int8(18)},
+			},
+		},
+		{
+			// 列不一样的行没办法放到一条语句里面
+			name: "skip zero multiple statements",
+			q: NewInserter[TestModel](db).SkipZero().
+				Values(&TestModel{FirstName: "Deng"}, &TestModel{Age: 18}),
+			wantErr: errs.ErrInsertMultipleStatements,
+		},
+		{
+			name:    "skip zero all zero",
+			q:       NewInserter[TestModel](db).SkipZero().Values(&TestModel{}),
+			wantErr: errs.ErrInsertNoColumns,
+		},
+		{
+			// 按照模型定义的顺序，而不是 map 的遍历顺序
+			name: "insert map",
+			q: NewInserter[TestModel](db).InsertMap(
+				map[string]any{"Age": 18, "FirstName": "Deng"},
+				map[string]any{"FirstName": "Da", "Age": 19}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model`(`first_name`, `age`) VALUES(?, ?), (?, ?);",
+				Args: []any{"Deng", 18, "Da", 19},
+			},
+		},
+		{
+			name:    "insert map unknown field",
+			q:       NewInserter[TestModel](db).InsertMap(map[string]any{"first_name": "Deng"}),
+			wantErr: errs.NewErrUnknownField("first_name"),
+		},
+		{
+			name: "insert map multiple statements",
+			q: NewInserter[TestModel](db).InsertMap(
+				map[string]any{"FirstName": "Deng"},
+				map[string]any{"Age": 19}),
+			wantErr: errs.ErrInsertMultipleStatements,
+		},
+		{
+			name:    "insert empty map",
+			q:       NewInserter[TestModel](db).InsertMap(map[string]any{}),
+			wantErr: errs.ErrInsertNoColumns,
+		},
+		{
+			name:    "invalid auto increment type",
+			q:       NewInserter[StringIdModel](db).Values(&StringIdModel{Name: "Deng"}),
+			wantErr: errs.NewErrInvalidAutoIncrementType("Id"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestInserter_Exec_LastInsertId(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	// MySQL 批量插入返回第一行的 ID
+	mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(10, 2))
+	mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(20, 1))
+
+	vals := []*TestModel{{FirstName: "Deng"}, {FirstName: "Da"}}
+	res := NewInserter[TestModel](db).Values(vals...).Exec(context.Background())
+	require.NoError(t, res.Err())
+	assert.Equal(t, int64(10), vals[0].Id)
+	assert.Equal(t, int64(11), vals[1].Id)
+
+	// 显式指定了 ID，不回写
+	val := &TestModel{Id: 3, FirstName: "Deng"}
+	res = NewInserter[TestModel](db).Values(val).Exec(context.Background())
+	require.NoError(t, res.Err())
+	assert.Equal(t, int64(3), val.Id)
+}
+
+func TestInserter_Exec_PointerAutoIncrement(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	// nil 指针的自增列是零值，不插入并且回写
+	mock.ExpectExec("INSERT INTO `pointer_id_model`\\(`name`\\) VALUES\\(\\?\\);").
+		WithArgs("Deng").WillReturnResult(sqlmock.NewResult(10, 1))
+	val := &PointerIdModel{Name: "Deng"}
+	require.NoError(t, NewInserter[PointerIdModel](db).Values(val).Exec(context.Background()).Err())
+	require.NotNil(t, val.Id)
+	assert.Equal(t, int64(10), *val.Id)
+}
+
+type PointerIdModel struct {
+	Id   *int64 `orm:"autoIncrement"`
+	Name string
+}
+
+type StringIdModel struct {
+	Id   string `orm:"autoIncrement"`
+	Name string
+}
+
+// sqlite 不保证 RETURNING 的顺序，按照 ID 从小到大回写
+func TestInserter_Exec_ReturningUnordered(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB, DBWithDialect(SQLite))
+	require.NoError(t, err)
+
+	mock.ExpectQuery("INSERT INTO `test_model`\\(`first_name`, `age`, `last_name`\\) VALUES\\(\\?, \\?, \\?\\), \\(\\?, \\?, \\?\\) RETURNING `id`;").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(43).AddRow(42))
+	vals := []*TestModel{{FirstName: "Deng"}, {FirstName: "Da"}}
+	res := NewInserter[TestModel](db).Values(vals...).Exec(context.Background())
+	require.NoError(t, res.Err())
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	assert.Equal(t, int64(43), id)
+	assert.Equal(t, int64(42), vals[0].Id)
+	assert.Equal(t, int64(43), vals[1].Id)
+}
+
+func TestInserter_Exec_Returning(t *testing.T) {
+	db, err := Open("sqlite3", "file:insert_returning.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE test_model(id INTEGER PRIMARY KEY AUTOINCREMENT, first_name TEXT, age INTEGER, last_name TEXT);" +
+		"INSERT INTO test_model(id) VALUES (41);")
+	require.NoError(t, err)
+
+	vals := []*TestModel{{FirstName: "Deng"}, {FirstName: "Da"}}
+	res := NewInserter[TestModel](db).Values(vals...).Exec(context.Background())
+	require.NoError(t, res.Err())
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	assert.Equal(t, int64(43), id)
+	assert.Equal(t, int64(42), vals[0].Id)
+	assert.Equal(t, int64(43), vals[1].Id)
+}
+
+func TestInserter_Chunk(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	vals := []*TestModel{{FirstName: "a"}, {FirstName: "b"}, {FirstName: "c"}, {FirstName: "d"}, {FirstName: "e"}}
+
+	// 5 行，每批 2 行
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `test_model`\\(`first_name`, `age`, `last_name`\\) VALUES\\(\\?, \\?, \\?\\), \\(\\?, \\?, \\?\\);").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(3, 2))
+	mock.ExpectExec("INSERT INTO `test_model`\\(`first_name`, `age`, `last_name`\\) VALUES\\(\\?, \\?, \\?\\);").
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+	res := NewInserter[TestModel](db).Values(vals...).Chunk(2).Exec(context.Background())
+	require.NoError(t, res.Err())
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), affected)
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), id)
+	for k, v := range vals {
+		assert.Equal(t, int64(k+1), v.Id)
+	}
+
+	// 任何一批失败都回滚
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec("INSERT INTO .*").WillReturnError(errors.New("mock error"))
+	mock.ExpectRollback()
+	res = NewInserter[TestModel](db).Values(vals...).Chunk(2).Exec(context.Background())
+	assert.Equal(t, errors.New("mock error"), res.Err())
+
+	// 在事务里面直接执行，不再开启新的事务
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(1, 3))
+	mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(4, 2))
+	mock.ExpectCommit()
+	err = db.DoTx(context.Background(), nil, func(ctx context.Context, tx *Tx) error {
+		return NewInserter[TestModel](tx).Values(vals...).Chunk(3).Exec(ctx).Err()
+	})
+	require.NoError(t, err)
+
+	// 没有超过上限的时候只执行一次，不开启事务
+	mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(1, 5))
+	res = NewInserter[TestModel](db).Values(vals...).Chunk(0).Exec(context.Background())
+	require.NoError(t, res.Err())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInserter_rowsPerChunk(t *testing.T) {
+	vals := []*TestModel{{Id: 1}}
+	testCases := []struct {
+		name    string
+		i       *Inserter[TestModel]
+		want    int
+		wantErr error
+	}{
+		{
+			name: "specified",
+			i:    NewInserter[TestModel](memoryDB(t)).Values(vals...).Chunk(10),
+			want: 10,
+		},
+		{
+			name:    "specified no rows",
+			i:       NewInserter[TestModel](memoryDB(t)).Chunk(10),
+			wantErr: errs.ErrInsertZeroRow,
+		},
+		{
+			name:    "no rows",
+			i:       NewInserter[TestModel](memoryDB(t)).Chunk(0),
+			wantErr: errs.ErrInsertZeroRow,
+		},
+		{
+			name: "mysql",
+			i:    NewInserter[TestModel](memoryDB(t)).Values(vals...).Chunk(0),
+			want: 65535 / 4,
+		},
+		{
+			name: "sqlite",
+			i:    NewInserter[TestModel](memoryDB(t, DBWithDialect(SQLite))).Values(vals...).Chunk(0),
+			want: 999 / 4,
+		},
+		{
+			name: "sqlite max placeholders",
+			i: NewInserter[TestModel](memoryDB(t, DBWithDialect(SQLiteWithMaxPlaceholders(32766)))).
+				Values(vals...).Chunk(0),
+			want: 32766 / 4,
+		},
+		{
+			name: "columns",
+			i:    NewInserter[TestModel](memoryDB(t)).Values(vals...).Columns("FirstName").Chunk(0),
+			want: 65535,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			size, err := tc.i.rowsPerChunk()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.want, size)
+		})
+	}
+}
+
+func TestInserter_Select_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:insert_select.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE test_model(id INTEGER PRIMARY KEY, first_name TEXT, age INTEGER, last_name TEXT);" +
+		"CREATE TABLE test_model_archive(id INTEGER PRIMARY KEY, first_name TEXT, age INTEGER, last_name TEXT);" +
+		"INSERT INTO test_model VALUES (1, 'a', 10, NULL), (2, 'b', 20, NULL), (3, 'c', 30, NULL);" +
+		"INSERT INTO test_model_archive VALUES (3, 'old', 30, NULL);")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	res := NewInserter[TestModelArchive](db).Ignore().
+		Select(NewSelector[TestModel](db).Where(Col("Age").GT(15))).Exec(ctx)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	archived, err := NewSelector[TestModelArchive](db).OrderBy(Asc("Id")).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*TestModelArchive{
+		{Id: 2, FirstName: "b", Age: 20},
+		{Id: 3, FirstName: "old", Age: 30},
+	}, archived)
+}
+
+func TestInserter_SkipZero_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:insert_skip_zero.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE test_model(id INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"first_name TEXT NOT NULL DEFAULT 'unknown', age INTEGER NOT NULL DEFAULT 18, last_name TEXT);")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	vals := []*TestModel{{FirstName: "a"}, {Age: 20}, {FirstName: "c"}}
+	res := NewInserter[TestModel](db).SkipZero().Values(vals...).Exec(ctx)
+	require.NoError(t, res.Err())
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+	// 分成两条语句，第一条插入第一行和第三行
+	assert.Equal(t, int64(1), vals[0].Id)
+	assert.Equal(t, int64(3), vals[1].Id)
+	assert.Equal(t, int64(2), vals[2].Id)
+
+	res = NewInserter[TestModel](db).InsertMap(
+		map[string]any{"FirstName": "d"},
+		map[string]any{"Age": 30, "LastName": "e"}).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	got, err := NewSelector[TestModel](db).OrderBy(Asc("Id")).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*TestModel{
+		{Id: 1, FirstName: "a", Age: 18},
+		{Id: 2, FirstName: "c", Age: 18},
+		{Id: 3, FirstName: "unknown", Age: 20},
+		{Id: 4, FirstName: "d", Age: 18},
+		{Id: 5, FirstName: "unknown", Age: 30, LastName: &sql.NullString{String: "e", Valid: true}},
+	}, got)
+
+	// 全是零值的行没有要插入的列，整个事务回滚
+	res = NewInserter[TestModel](db).SkipZero().Values(&TestModel{FirstName: "f"}, &TestModel{}).Exec(ctx)
+	assert.Equal(t, errs.ErrInsertNoColumns, res.Err())
+	got, err = NewSelector[TestModel](db).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, len(got))
+}
+
+func TestInserter_Timestamps(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	db := memoryDB(t, DBWithClock(func() time.Time { return now }))
+	created := time.UnixMilli(1600000000000)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "values",
+			q:    NewInserter[TimeModel](db).Values(&TimeModel{Id: 1, Name: "Tom"}),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `time_model`(`id`, `name`, `created_at`, `created_unix`, `updated_at`, `updated_time`) " +
+					"VALUES(?, ?, ?, ?, ?, ?);",
+				Args: []any{int64(1), "Tom", now, uint32(1700000000), int64(1700000000123), &now},
+			},
+		},
+		{
+			// 已经有值的字段不覆盖
+			name: "keep non-zero",
+			q: NewInserter[TimeModel](db).Values(&TimeModel{Id: 1, CreatedAt: created}).
+				Columns("Id", "CreatedAt"),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `time_model`(`id`, `created_at`) VALUES(?, ?);",
+				Args: []any{int64(1), created},
+			},
+		},
+		{
+			name: "insert map",
+			q:    NewInserter[TimeModel](db).InsertMap(map[string]any{"Name": "Tom", "CreatedUnix": 1}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `time_model`(`name`, `created_at`, `created_unix`, `updated_at`, `updated_time`) VALUES(?, ?, ?, ?, ?);",
+				Args: []any{"Tom", now, 1, int64(1700000000123), &now},
+			},
+		},
+		{
+			// 冲突的时候更新时间使用当前时间，而不是实体里面旧的时间
+			name: "upsert",
+			q: NewInserter[TimeModel](db).Values(&TimeModel{Id: 1, Name: "Tom", CreatedAt: created,
+				CreatedUnix: 1600000000, UpdatedAt: 1600000000000, UpdatedTime: &created}).
+				Upsert().Update(Col("Name"), Col("UpdatedAt")),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `time_model`(`id`, `name`, `created_at`, `created_unix`, `updated_at`, `updated_time`) " +
+					"VALUES(?, ?, ?, ?, ?, ?) " +
+					"ON DUPLICATE KEY UPDATE `name`=VALUES(`name`), `updated_at`=?, `updated_time`=?;",
+				Args: []any{int64(1), "Tom", created, uint32(1600000000), int64(1700000000123), &now,
+					int64(1700000000123), &now},
+			},
+		},
+		{
+			name:    "invalid type",
+			q:       NewInserter[InvalidTimeModel](db).Values(&InvalidTimeModel{}),
+			wantErr: errs.NewErrInvalidAutoTimeType("CreatedAt"),
+		},
+		{
+			name:    "invalid unit",
+			q:       NewInserter[InvalidTimeUnitModel](db).Values(&InvalidTimeUnitModel{}),
+			wantErr: errs.NewErrInvalidTagContent("nano"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -150,3 +683,61 @@ func TestInserter_Build(t *testing.T) {
 		})
 	}
 }
+
+// Build 不修改调用者的数据，Exec 只把时间写回结构体
+func TestInserter_Timestamps_Exec(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB, DBWithClock(func() time.Time { return now }))
+	require.NoError(t, err)
+
+	tm := &TimeModel{Id: 1, Name: "Tom"}
+	row := map[string]any{"Id": 2, "Name": "Jerry"}
+	_, err = NewInserter[TimeModel](db).Values(tm).Build()
+	require.NoError(t, err)
+	_, err = NewInserter[TimeModel](db).InsertMap(row).Build()
+	require.NoError(t, err)
+	assert.Equal(t, &TimeModel{Id: 1, Name: "Tom"}, tm)
+	assert.Equal(t, map[string]any{"Id": 2, "Name": "Jerry"}, row)
+
+	mock.ExpectExec("INSERT INTO `time_model`.*").
+		WithArgs(int64(1), "Tom", now, uint32(1700000000), int64(1700000000123), &now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `time_model`.*").
+		WithArgs(2, "Jerry", now, uint32(1700000000), int64(1700000000123), &now).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	require.NoError(t, NewInserter[TimeModel](db).Values(tm).Exec(context.Background()).Err())
+	require.NoError(t, NewInserter[TimeModel](db).InsertMap(row).Exec(context.Background()).Err())
+	assert.Equal(t, &TimeModel{Id: 1, Name: "Tom", CreatedAt: now, CreatedUnix: 1700000000,
+		UpdatedAt: 1700000000123, UpdatedTime: &now}, tm)
+	assert.Equal(t, map[string]any{"Id": 2, "Name": "Jerry"}, row)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type TimeModel struct {
+	Id          int64
+	Name        string
+	CreatedAt   time.Time  `orm:"autoCreateTime"`
+	CreatedUnix uint32     `orm:"autoCreateTime"`
+	UpdatedAt   int64      `orm:"autoCreateTime=milli,autoUpdateTime=milli"`
+	UpdatedTime *time.Time `orm:"autoUpdateTime"`
+}
+
+type InvalidTimeModel struct {
+	Id        int64
+	CreatedAt string `orm:"autoCreateTime"`
+}
+
+type InvalidTimeUnitModel struct {
+	Id        int64
+	CreatedAt int64 `orm:"autoCreateTime=nano"`
+}
+
+type TestModelArchive struct {
+	Id        int64
+	FirstName string
+	Age       int8
+	LastName  *sql.NullString
+}
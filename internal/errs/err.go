@@ -16,6 +16,30 @@ var (
 	// ErrInsertZeroRow 代表插入 0 行
 	ErrInsertZeroRow    = errors.New("orm: 插入 0 行")
 	ErrNoUpdatedColumns = errors.New("orm: 未指定更新的列")
+	// ErrLockOutsideTx 行锁只有在事务里面才有意义
+	ErrLockOutsideTx = errors.New("orm: FOR UPDATE/FOR SHARE 只能在事务中使用")
+	// ErrLockWaitWithoutMode 调用了 NoWait 或者 SkipLocked，但是没有调用 ForUpdate 或者 ForShare
+	ErrLockWaitWithoutMode = errors.New("orm: NOWAIT/SKIP LOCKED 需要和 FOR UPDATE/FOR SHARE 一起使用")
+	// ErrSetColumnsMismatch UNION 等组合查询的子查询列数不一致
+	ErrSetColumnsMismatch = errors.New("orm: 组合查询的子查询列数不一致")
+	// ErrEmptyCase CASE 表达式至少需要一个 WHEN
+	ErrEmptyCase = errors.New("orm: CASE 表达式缺少 WHEN")
+	// ErrTooManyReturnedRows RETURNING 返回的行数比插入的行数多
+	ErrTooManyReturnedRows = errors.New("orm: 返回的行数过多")
+	// ErrInsertColumnsMismatch INSERT ... SELECT 的列数不一致
+	ErrInsertColumnsMismatch = errors.New("orm: 插入的列数和查询的列数不一致")
+	// ErrNoPrimaryKey 模型没有主键，既没有标记 primaryKey 也没有 Id 字段
+	ErrNoPrimaryKey = errors.New("orm: 模型没有主键")
+	// ErrInsertMultipleStatements 跳过零值或者插入 map 的时候，各行插入的列不一样，
+	// 没办法用一条语句表达，只能通过 Exec 拆成多条语句执行
+	ErrInsertMultipleStatements = errors.New("orm: 各行插入的列不一致，需要拆成多条语句")
+	// ErrInsertNoColumns 跳过零值之后或者 map 为空的时候，一行没有任何要插入的列
+	ErrInsertNoColumns = errors.New("orm: 没有需要插入的列")
+	// ErrZeroPrimaryKey 传入了实体但是没有调用 Where，而实体的主键都是零值，
+	// 按照主键更新或者删除不会影响任何行
+	ErrZeroPrimaryKey = errors.New("orm: 实体的主键都是零值，无法确定要操作的行")
+	// ErrStaleObject 乐观锁更新失败，数据已经被其它人修改或者删除了
+	ErrStaleObject = errors.New("orm: 数据已经被修改，版本号不一致")
 )
 
 // NewErrUnknownField 返回代表未知字段的错误
@@ -32,6 +56,28 @@ func NewErrUnknownColumn(col string) error {
 	return fmt.Errorf("orm: 未知列 %s", col)
 }
 
+// NewErrUnknownCTE 返回未定义的 CTE 的错误，
+// 一般意味着在 From 或者 Join 里面引用 CTE 之前没有调用 With
+func NewErrUnknownCTE(name string) error {
+	return fmt.Errorf("orm: 未定义的 CTE %s", name)
+}
+
+// NewErrUnknownAlias 返回 SELECT 列表中不存在该别名的错误
+func NewErrUnknownAlias(alias string) error {
+	return fmt.Errorf("orm: 未知别名 %s", alias)
+}
+
+// NewErrPrimaryKeyCount 返回主键的值的数量和主键数量不一致的错误，
+// 复合主键需要按照字段定义的顺序传入所有主键的值
+func NewErrPrimaryKeyCount(want int, got int) error {
+	return fmt.Errorf("orm: 主键有 %d 个，但是传入了 %d 个值", want, got)
+}
+
+// NewErrUnsupportedTable 返回不支持的 TableReference 的错误
+func NewErrUnsupportedTable(tbl any) error {
+	return fmt.Errorf("orm: 不支持的表 %v", tbl)
+}
+
 func NewErrUnsupportedAssignableType(exp any) error {
 	return fmt.Errorf("orm: 不支持的 Assignable 表达式 %v", exp)
 }
@@ -57,6 +103,97 @@ func NewErrUnsupportedSelectable(exp any) error {
 // 发生该错误，主要是因为传入了不支持的 Expression 的实际类型
 // 一般来说，这是因为中间件
 
+// NewErrDialectUnsupported 返回方言不支持某个特性的错误，
+// 例如 sqlite 不支持 FOR UPDATE
+func NewErrDialectUnsupported(dialect string, feature string) error {
+	return fmt.Errorf("orm: %s 不支持 %s", dialect, feature)
+}
+
+// NewErrMultipleAutoIncrement 一个模型只能有一个自增列
+func NewErrMultipleAutoIncrement(fd1 string, fd2 string) error {
+	return fmt.Errorf("orm: 自增列只能有一个，但是 %s 和 %s 都是自增列", fd1, fd2)
+}
+
+// NewErrMultipleVersion 一个模型只能有一个版本号字段
+func NewErrMultipleVersion(fd1 string, fd2 string) error {
+	return fmt.Errorf("orm: 版本号字段只能有一个，但是 %s 和 %s 都是版本号字段", fd1, fd2)
+}
+
+// NewErrInvalidAutoIncrementType 自增列必须是整数或者整数指针
+func NewErrInvalidAutoIncrementType(fd string) error {
+	return fmt.Errorf("orm: 自增列 %s 必须是整数或者整数指针", fd)
+}
+
+// NewErrInvalidVersionType 版本号字段必须是整数
+func NewErrInvalidVersionType(fd string) error {
+	return fmt.Errorf("orm: 版本号字段 %s 必须是整数", fd)
+}
+
+// NewErrInvalidAutoTimeType 自动维护的时间字段只能是 time.Time、*time.Time 或者整数
+func NewErrInvalidAutoTimeType(fd string) error {
+	return fmt.Errorf("orm: 时间字段 %s 必须是 time.Time、*time.Time、sql.NullTime、Null[time.Time] 或者整数", fd)
+}
+
+// NewErrMultipleSoftDelete 一个模型只能有一个软删除字段
+func NewErrMultipleSoftDelete(fd1 string, fd2 string) error {
+	return fmt.Errorf("orm: 软删除字段只能有一个，但是 %s 和 %s 都是软删除字段", fd1, fd2)
+}
+
+// NewErrInvalidSoftDeleteType 软删除字段需要能够表示 NULL
+func NewErrInvalidSoftDeleteType(fd string) error {
+	return fmt.Errorf("orm: 软删除字段 %s 必须是 *time.Time、sql.NullTime 或者 Null[time.Time]", fd)
+}
+
+// NewErrFieldValidation 字段没有通过校验，多个字段的错误会通过 multierr 合并
+func NewErrFieldValidation(fd string, reason string) error {
+	return fmt.Errorf("orm: 字段 %s 校验失败，%s", fd, reason)
+}
+
+// NewErrInvalidEmbeddedType 只有结构体可以展开，不支持指针
+func NewErrInvalidEmbeddedType(fd string) error {
+	return fmt.Errorf("orm: 嵌入字段 %s 必须是结构体", fd)
+}
+
+// NewErrAmbiguousField 嵌入的结构体在同一层有同名字段
+func NewErrAmbiguousField(fd string) error {
+	return fmt.Errorf("orm: 字段 %s 有歧义，多个嵌入的结构体都有这个字段", fd)
+}
+
+// NewErrDuplicateColumn 两个字段映射到了同一个列
+func NewErrDuplicateColumn(fd1 string, fd2 string, col string) error {
+	return fmt.Errorf("orm: 字段 %s 和 %s 的列名都是 %s", fd1, fd2, col)
+}
+
+// NewErrUnsupportedFieldValue 值无法转换成字段的类型
+func NewErrUnsupportedFieldValue(fd string, val any) error {
+	return fmt.Errorf("orm: 无法把 %v 写入字段 %s", val, fd)
+}
+
+// NewErrUnsupportedFieldType 字段的类型既不是基础类型，也没有实现 sql.Scanner 或者 driver.Valuer
+func NewErrUnsupportedFieldType(fd string, typ any) error {
+	return fmt.Errorf("orm: 不支持字段 %s 的类型 %v，需要实现 sql.Scanner 和 driver.Valuer", fd, typ)
+}
+
+// NewErrInvalidJSONSource 数据库返回的值无法作为 JSON 解析
+func NewErrInvalidJSONSource(src any) error {
+	return fmt.Errorf("orm: 无法把 %T 类型的值作为 JSON 解析", src)
+}
+
+// NewErrInvalidJSONPath JSON 路径必须以 $ 开头
+func NewErrInvalidJSONPath(path string) error {
+	return fmt.Errorf("orm: 错误的 JSON 路径 %s，需要以 $ 开头", path)
+}
+
+// NewErrUnsupportedScanSource 数据库返回的值无法转换成目标类型
+func NewErrUnsupportedScanSource(src any, typ any) error {
+	return fmt.Errorf("orm: 无法把 %T 类型的值 %v 转换成 %v", src, src, typ)
+}
+
+// NewErrStaleAccessor toyorm-gen 生成的代码和模型的字段对不上
+func NewErrStaleAccessor(table string) error {
+	return fmt.Errorf("orm: 模型 %s 生成的代码已经过期，需要重新运行 toyorm-gen", table)
+}
+
 func NewErrInvalidTagContent(tag string) error {
 	return fmt.Errorf("orm: 错误的标签设置: %s", tag)
 }
@@ -0,0 +1,36 @@
+package model
+
+import "github.com/aristletl/toyorm/internal/errs"
+
+// Accessor 由 toyorm-gen 为模型生成，不通过反射读写字段。
+// 模型的指针实现了这个接口的时候，Registry 会自动记录每个字段在生成的代码里面的下标
+type Accessor interface {
+	// ToyormFieldNames 返回生成代码时模型的字段名，顺序就是其余两个方法使用的下标
+	ToyormFieldNames() []string
+	// ToyormField 返回第 i 个字段写入数据库的值，nil 指针返回 nil
+	ToyormField(i int) any
+	// ToyormFieldPtr 返回第 i 个字段的地址，用作 rows.Scan 的目标
+	ToyormFieldPtr(i int) any
+}
+
+// bindAccessor 把生成的代码里面的下标记录到字段上，
+// 生成的代码和模型不一致的时候返回错误，说明需要重新生成
+func bindAccessor(m *Model, val any) error {
+	acc, ok := val.(Accessor)
+	if !ok {
+		return nil
+	}
+	names := acc.ToyormFieldNames()
+	if len(names) != len(m.Columns) {
+		return errs.NewErrStaleAccessor(m.TableName)
+	}
+	for i, name := range names {
+		fd, ok := m.FieldMap[name]
+		if !ok {
+			return errs.NewErrStaleAccessor(m.TableName)
+		}
+		fd.AccessorIndex = i
+	}
+	m.Accessor = true
+	return nil
+}
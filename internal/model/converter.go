@@ -0,0 +1,48 @@
+package model
+
+import (
+	"database/sql/driver"
+	"reflect"
+)
+
+// Converter 在 Go 类型和数据库支持的类型之间转换，
+// 用于无法实现 sql.Scanner 和 driver.Valuer 的类型，例如第三方库的类型
+type Converter interface {
+	// ToDB 把 Go 的值转换成写入数据库的值，val 是注册的类型
+	ToDB(val any) (driver.Value, error)
+	// FromDB 把数据库返回的 src 转换之后写入 dst，
+	// dst 是指向注册的类型的指针，src 和 sql.Scanner 收到的一样，可能是 nil
+	FromDB(src any, dst any) error
+}
+
+// RegisterConverter 注册 typ 类型的转换器，*typ 类型的字段也会使用这个转换器。
+// 已经解析过的模型不会受影响，所以需要在使用模型之前注册
+func (r *Registry) RegisterConverter(typ reflect.Type, c Converter) {
+	r.converters.Store(typ, c)
+}
+
+// Converter 查找 typ 类型的转换器
+func (r *Registry) Converter(typ reflect.Type) (Converter, bool) {
+	c, ok := r.converters.Load(typ)
+	if !ok {
+		return nil, false
+	}
+	return c.(Converter), true
+}
+
+// fieldConverter 查找字段的转换器，指针字段使用指向的类型的转换器，
+// nil 指针对应 NULL
+func (r *Registry) fieldConverter(typ reflect.Type) Converter {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	c, _ := r.Converter(typ)
+	return c
+}
+
+func WithConverter(typ reflect.Type, c Converter) Option {
+	return func(r *Registry) error {
+		r.RegisterConverter(typ, c)
+		return nil
+	}
+}
@@ -2,7 +2,17 @@
 // 些元数据将被用于构建 SQL、执行校验，以及用于处理结果集。
 package model
 
-import "reflect"
+import (
+	"reflect"
+	"regexp"
+)
+
+const (
+	// TimeUnitSecond 整数类型的时间字段保存 Unix 秒，默认值
+	TimeUnitSecond = "sec"
+	// TimeUnitMilli 整数类型的时间字段保存 Unix 毫秒
+	TimeUnitMilli = "milli"
+)
 
 // Model 用于定义数据到数据库表的映射关系
 type Model struct {
@@ -11,12 +21,71 @@ type Model struct {
 	Columns  []*Field
 	FieldMap map[string]*Field
 	ColMap   map[string]*Field
+	// PrimaryKeys 主键，按照字段定义的顺序排列，复合主键会有多个
+	PrimaryKeys []*Field
+	// AutoIncrement 自增列，没有的话为 nil
+	AutoIncrement *Field
+	// Version 乐观锁的版本号字段，没有的话为 nil
+	Version *Field
+	// SoftDelete 软删除的时间字段，没有的话为 nil
+	SoftDelete *Field
+	// Accessor 模型是否有 toyorm-gen 生成的 Accessor
+	Accessor bool
 }
 
 // Field field字段
 type Field struct {
-	Index   int
-	ColName string
-	Type    reflect.Type
-	Offset  uintptr
+	// Name 字段名，展开的具名结构体字段是 Addr.City 的形式
+	Name string
+	// Index 在 Model.Columns 里面的下标
+	Index int
+	// IndexPath reflect 的字段下标路径，用于 reflect.Value.FieldByIndex
+	IndexPath []int
+	ColName   string
+	Type      reflect.Type
+	// Offset 相对于模型的偏移量，嵌入的结构体的字段已经加上了结构体本身的偏移量
+	Offset uintptr
+	// PrimaryKey 是否是主键
+	PrimaryKey bool
+	// AutoIncrement 是否是自增列
+	AutoIncrement bool
+	// Version 是否是乐观锁的版本号
+	Version bool
+	// AutoCreateTime 插入的时候自动写入当前时间
+	AutoCreateTime bool
+	// AutoUpdateTime 插入和更新的时候自动写入当前时间
+	AutoUpdateTime bool
+	// TimeUnit 整数类型的时间字段保存的单位，TimeUnitSecond 或者 TimeUnitMilli
+	TimeUnit string
+	// SoftDelete 是否是软删除的时间字段，为 NULL 的时候代表没有被删除
+	SoftDelete bool
+	// Validation 插入和更新之前的校验规则，没有的话为 nil
+	Validation *Validation
+	// Converter 字段类型注册的转换器，指针字段使用指向的类型的转换器，没有的话为 nil
+	Converter Converter
+	// AccessorIndex 在生成的 Accessor 里面的下标，Model.Accessor 为 false 的时候没有意义
+	AccessorIndex int
+
+	// depth 嵌入的层数，解析的时候处理同名字段
+	depth int
+}
+
+// Nullable 由可以为 NULL 的包装类型实现，例如 toyorm.Null[T]，
+// ValueType 返回被包装的类型，用于校验字段的类型
+type Nullable interface {
+	ValueType() reflect.Type
+}
+
+// Validation 字段的校验规则，解析自 notnull、size、min、max、regex 标签
+type Validation struct {
+	// NotNull 值不能是 NULL，例如 nil 指针或者 Valid 为 false 的 sql.NullString
+	NotNull bool
+	// Size 字符串或者切片的最大长度，字符串按照字符计算，0 代表不限制
+	Size int
+	// Min 数字的最小值，nil 代表不限制
+	Min *float64
+	// Max 数字的最大值，nil 代表不限制
+	Max *float64
+	// Regex 字符串需要匹配的正则表达式
+	Regex *regexp.Regexp
 }
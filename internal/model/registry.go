@@ -1,11 +1,72 @@
 package model
 
 import (
-	"github.com/aristletl/toyorm/internal/errs"
+	"database/sql"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+const (
+	tagName = "orm"
+	// tagColumn 指定列名，例如 orm:"column=user_id"
+	tagColumn = "column"
+	// tagPrimaryKey 标记主键，没有任何字段标记的时候 Id 字段就是主键
+	tagPrimaryKey = "primaryKey"
+	// tagAutoIncrement 标记自增列，唯一的整数主键默认就是自增列
+	tagAutoIncrement = "autoIncrement"
+	// tagVersion 标记乐观锁的版本号，必须是整数
+	tagVersion = "version"
+	// tagAutoCreateTime 插入的时候自动写入当前时间，
+	// 整数类型可以指定单位，例如 orm:"autoCreateTime=milli"
+	tagAutoCreateTime = "autoCreateTime"
+	// tagAutoUpdateTime 插入和更新的时候自动写入当前时间，单位和 tagAutoCreateTime 一样
+	tagAutoUpdateTime = "autoUpdateTime"
+	// tagSoftDelete 标记软删除的时间字段，必须是 *time.Time 或者 sql.NullTime
+	tagSoftDelete = "softDelete"
+
+	// tagNotNull 值不能是 NULL
+	tagNotNull = "notnull"
+	// tagSize 字符串或者切片的最大长度，例如 orm:"size=64"
+	tagSize = "size"
+	// tagMin 数字的最小值，例如 orm:"min=0"
+	tagMin = "min"
+	// tagMax 数字的最大值，例如 orm:"max=150"
+	tagMax = "max"
+	// tagRegex 字符串需要匹配的正则表达式，例如 orm:"regex=^[a-z]+$"，
+	// 因为标签使用逗号分隔，所以正则表达式里面不能有逗号
+	tagRegex = "regex"
+
+	// tagEmbedded 展开具名的结构体字段，字段名是 Addr.City 的形式，
+	// 匿名嵌入的结构体默认展开，字段名和 Go 的字段提升一致
+	tagEmbedded = "embedded"
+	// tagPrefix 展开的结构体的列名前缀，例如 orm:"embedded,prefix=addr_"
+	tagPrefix = "prefix"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	nullableType = reflect.TypeOf((*Nullable)(nil)).Elem()
+
+	// sqlNullTypes database/sql 里面可以为 NULL 的类型到实际的值的类型
+	sqlNullTypes = map[reflect.Type]reflect.Type{
+		reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+		reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+		reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+		reflect.TypeOf(sql.NullInt16{}):   reflect.TypeOf(int16(0)),
+		reflect.TypeOf(sql.NullByte{}):    reflect.TypeOf(byte(0)),
+		reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+		reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+		nullTimeType:                      timeType,
+	}
 )
 
 type Option func(r *Registry) error
@@ -15,6 +76,8 @@ type Underscore func(name string) string
 type Registry struct {
 	UnderscoreName Underscore
 	models         sync.Map
+	// converters reflect.Type 到 Converter
+	converters sync.Map
 }
 
 func NewRegistry(opts ...Option) (*Registry, error) {
@@ -61,31 +124,359 @@ func (r *Registry) parseModel(val any) (*Model, error) {
 	}
 	typ = typ.Elem()
 
-	// 获得字段的数量
+	cols, err := r.parseFields(typ, fieldPath{depth: 0})
+	if err != nil {
+		return nil, err
+	}
+	cols, err = resolveFields(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldMap := make(map[string]*Field, len(cols))
+	colMap := make(map[string]*Field, len(cols))
+	var (
+		pks     []*Field
+		autoInc *Field
+		version *Field
+		deleted *Field
+	)
+	for i, f := range cols {
+		f.Index = i
+		fieldMap[f.Name] = f
+		colMap[f.ColName] = f
+		if f.PrimaryKey {
+			pks = append(pks, f)
+		}
+		if f.AutoIncrement {
+			if autoInc != nil {
+				return nil, errs.NewErrMultipleAutoIncrement(autoInc.Name, f.Name)
+			}
+			// 生成的 ID 是整数，回写的时候只能写到整数或者整数指针里面
+			if typ := f.Type; !isInteger(typ) && (typ.Kind() != reflect.Pointer || !isInteger(typ.Elem())) {
+				return nil, errs.NewErrInvalidAutoIncrementType(f.Name)
+			}
+			autoInc = f
+		}
+		if f.Version {
+			if version != nil {
+				return nil, errs.NewErrMultipleVersion(version.Name, f.Name)
+			}
+			if !isInteger(f.Type) {
+				return nil, errs.NewErrInvalidVersionType(f.Name)
+			}
+			version = f
+		}
+		if f.SoftDelete {
+			if deleted != nil {
+				return nil, errs.NewErrMultipleSoftDelete(deleted.Name, f.Name)
+			}
+			if typ, ok := valueType(f.Type); !ok || typ != timeType {
+				return nil, errs.NewErrInvalidSoftDeleteType(f.Name)
+			}
+			deleted = f
+		}
+	}
+
+	// 约定优于配置，没有标记主键就用 Id
+	if len(pks) == 0 {
+		if id, ok := fieldMap["Id"]; ok {
+			id.PrimaryKey = true
+			pks = append(pks, id)
+		}
+	}
+
+	if autoInc == nil && len(pks) == 1 && isInteger(pks[0].Type) {
+		autoInc = pks[0]
+		autoInc.AutoIncrement = true
+	}
+
+	res := &Model{
+		TableName:     r.UnderscoreName(typ.Name()),
+		Columns:       cols,
+		FieldMap:      fieldMap,
+		ColMap:        colMap,
+		PrimaryKeys:   pks,
+		AutoIncrement: autoInc,
+		Version:       version,
+		SoftDelete:    deleted,
+	}
+	if err = bindAccessor(res, val); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// fieldPath 嵌入的结构体的字段相对于模型的位置
+type fieldPath struct {
+	// index reflect 的字段下标路径
+	index []int
+	// offset 嵌入的结构体相对于模型的偏移量
+	offset uintptr
+	// name 字段名前缀，具名的嵌入字段是 Addr.，匿名的为空
+	name string
+	// col 列名前缀，来自 prefix 标签
+	col string
+	// depth 嵌入的层数，同名字段使用层数最少的，和 Go 的字段提升规则一致
+	depth int
+}
+
+// parseFields 解析结构体的字段，匿名嵌入的结构体和标记了 embedded 的结构体会被展开
+func (r *Registry) parseFields(typ reflect.Type, path fieldPath) ([]*Field, error) {
 	numField := typ.NumField()
-	fieldMap := make(map[string]*Field, numField)
-	colMap := make(map[string]*Field, numField)
-	cols := make([]*Field, numField)
+	res := make([]*Field, 0, numField)
 	for i := 0; i < numField; i++ {
 		fdType := typ.Field(i)
-		colName := r.UnderscoreName(fdType.Name)
-		f := &Field{
-			Index:   i,
-			ColName: colName,
-			Type:    fdType.Type,
-			Offset:  fdType.Offset,
-		}
-		fieldMap[fdType.Name] = f
-		colMap[colName] = f
-		cols[i] = f
-	}
-
-	return &Model{
-		TableName: r.UnderscoreName(typ.Name()),
-		Columns:   cols,
-		FieldMap:  fieldMap,
-		ColMap:    colMap,
-	}, nil
+		tags, err := r.parseTag(fdType.Tag)
+		if err != nil {
+			return nil, err
+		}
+		index := append(path.index[:len(path.index):len(path.index)], i)
+
+		_, isEmbedded := tags[tagEmbedded]
+		if fdType.Anonymous && fdType.Type.Kind() == reflect.Pointer && r.isFlattenable(fdType.Type.Elem()) {
+			// 嵌入的指针可能是 nil，没办法通过偏移量访问
+			return nil, errs.NewErrInvalidEmbeddedType(path.name + fdType.Name)
+		}
+		if fdType.Anonymous && r.isFlattenable(fdType.Type) || isEmbedded {
+			if fdType.Type.Kind() != reflect.Struct {
+				return nil, errs.NewErrInvalidEmbeddedType(path.name + fdType.Name)
+			}
+			sub := fieldPath{
+				index:  index,
+				offset: path.offset + fdType.Offset,
+				name:   path.name,
+				col:    path.col + tags[tagPrefix],
+				depth:  path.depth + 1,
+			}
+			if !fdType.Anonymous {
+				sub.name = path.name + fdType.Name + "."
+			}
+			fields, err := r.parseFields(fdType.Type, sub)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, fields...)
+			continue
+		}
+		if _, ok := tags[tagPrefix]; ok {
+			return nil, errs.NewErrInvalidTagContent(tagPrefix + "=" + tags[tagPrefix])
+		}
+
+		f, err := r.parseField(fdType, tags, path)
+		if err != nil {
+			return nil, err
+		}
+		f.IndexPath = index
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+func (r *Registry) parseField(fdType reflect.StructField, tags map[string]string, path fieldPath) (*Field, error) {
+	colName := tags[tagColumn]
+	if colName == "" {
+		colName = r.UnderscoreName(fdType.Name)
+	}
+	_, isPK := tags[tagPrimaryKey]
+	_, isAutoInc := tags[tagAutoIncrement]
+	_, isVersion := tags[tagVersion]
+	createUnit, isAutoCreate := tags[tagAutoCreateTime]
+	updateUnit, isAutoUpdate := tags[tagAutoUpdateTime]
+	_, isSoftDelete := tags[tagSoftDelete]
+	f := &Field{
+		Name:           path.name + fdType.Name,
+		ColName:        path.col + colName,
+		Type:           fdType.Type,
+		Offset:         path.offset + fdType.Offset,
+		PrimaryKey:     isPK,
+		AutoIncrement:  isAutoInc,
+		Version:        isVersion,
+		AutoCreateTime: isAutoCreate,
+		AutoUpdateTime: isAutoUpdate,
+		SoftDelete:     isSoftDelete,
+		Converter:      r.fieldConverter(fdType.Type),
+		depth:          path.depth,
+	}
+	var err error
+	if f.Validation, err = parseValidation(tags); err != nil {
+		return nil, err
+	}
+	if isAutoCreate || isAutoUpdate {
+		if f.TimeUnit, err = timeUnit(f, createUnit, updateUnit); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// resolveFields 处理同名的字段：层数少的字段覆盖层数多的，
+// 层数最少的那一层有多个同名字段的时候，和 Go 一样是有歧义的，返回错误。
+// 更深的层里面的同名字段不影响结果。列名也不能重复
+func resolveFields(fields []*Field) ([]*Field, error) {
+	minDepth := make(map[string]int, len(fields))
+	for _, f := range fields {
+		if depth, ok := minDepth[f.Name]; !ok || f.depth < depth {
+			minDepth[f.Name] = f.depth
+		}
+	}
+	byName := make(map[string]*Field, len(minDepth))
+	for _, f := range fields {
+		if f.depth != minDepth[f.Name] {
+			continue
+		}
+		if _, ok := byName[f.Name]; ok {
+			return nil, errs.NewErrAmbiguousField(f.Name)
+		}
+		byName[f.Name] = f
+	}
+	res := make([]*Field, 0, len(byName))
+	cols := make(map[string]*Field, len(byName))
+	for _, f := range fields {
+		if byName[f.Name] != f {
+			continue
+		}
+		if old, ok := cols[f.ColName]; ok {
+			return nil, errs.NewErrDuplicateColumn(old.Name, f.Name, f.ColName)
+		}
+		cols[f.ColName] = f
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+// isFlattenable 匿名嵌入的结构体是否需要展开。
+// time.Time、实现了 sql.Scanner 的结构体以及注册了 Converter 的结构体本身就是一个列，
+// 例如 sql.NullString
+func (r *Registry) isFlattenable(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+	if _, ok := r.Converter(typ); ok {
+		return false
+	}
+	return !reflect.PointerTo(typ).Implements(scannerType)
+}
+
+// parseTag 把 orm:"key1=value1,key2" 解析成 map，
+// 没有值的 key 对应空字符串
+func (r *Registry) parseTag(tag reflect.StructTag) (map[string]string, error) {
+	ormTag, ok := tag.Lookup(tagName)
+	if !ok || ormTag == "" {
+		return map[string]string{}, nil
+	}
+	pairs := strings.Split(ormTag, ",")
+	res := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		switch key {
+		case tagColumn, tagPrimaryKey, tagAutoIncrement, tagVersion,
+			tagAutoCreateTime, tagAutoUpdateTime, tagSoftDelete,
+			tagNotNull, tagSize, tagMin, tagMax, tagRegex, tagEmbedded, tagPrefix:
+		default:
+			return nil, errs.NewErrInvalidTagContent(pair)
+		}
+		if len(kv) == 1 {
+			res[key] = ""
+		} else {
+			res[key] = strings.TrimSpace(kv[1])
+		}
+	}
+	return res, nil
+}
+
+// parseValidation 解析校验规则，没有任何规则的时候返回 nil
+func parseValidation(tags map[string]string) (*Validation, error) {
+	var (
+		v   Validation
+		has bool
+	)
+	if _, ok := tags[tagNotNull]; ok {
+		v.NotNull, has = true, true
+	}
+	if size, ok := tags[tagSize]; ok {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			return nil, errs.NewErrInvalidTagContent(tagSize + "=" + size)
+		}
+		v.Size, has = n, true
+	}
+	for _, key := range []string{tagMin, tagMax} {
+		str, ok := tags[key]
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, errs.NewErrInvalidTagContent(key + "=" + str)
+		}
+		if key == tagMin {
+			v.Min = &f
+		} else {
+			v.Max = &f
+		}
+		has = true
+	}
+	if expr, ok := tags[tagRegex]; ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errs.NewErrInvalidTagContent(tagRegex + "=" + expr)
+		}
+		v.Regex, has = re, true
+	}
+	if !has {
+		return nil, nil
+	}
+	return &v, nil
+}
+
+// timeUnit 校验时间字段的类型，并且返回整数类型的时间单位，
+// 同时标记了 autoCreateTime 和 autoUpdateTime 的时候单位必须一致
+func timeUnit(f *Field, units ...string) (string, error) {
+	if typ, _ := valueType(f.Type); f.Type != timeType && typ != timeType && !isInteger(f.Type) {
+		return "", errs.NewErrInvalidAutoTimeType(f.Name)
+	}
+	res := ""
+	for _, unit := range units {
+		switch unit {
+		case "":
+			continue
+		case TimeUnitSecond, TimeUnitMilli:
+		default:
+			return "", errs.NewErrInvalidTagContent(unit)
+		}
+		if res != "" && res != unit {
+			return "", errs.NewErrInvalidTagContent(unit)
+		}
+		res = unit
+	}
+	if res == "" && isInteger(f.Type) {
+		res = TimeUnitSecond
+	}
+	return res, nil
+}
+
+// valueType 返回可以为 NULL 的类型实际的值的类型，
+// 例如 *time.Time、sql.NullTime 以及 Null[time.Time] 都返回 time.Time
+func valueType(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem(), true
+	}
+	if typ.Implements(nullableType) {
+		return reflect.Zero(typ).Interface().(Nullable).ValueType(), true
+	}
+	res, ok := sqlNullTypes[typ]
+	return res, ok
+}
+
+func isInteger(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
 }
 
 // underscoreName 驼峰转字符串命名
@@ -0,0 +1,110 @@
+package valuer
+
+import (
+	"database/sql"
+	"reflect"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/aristletl/toyorm/internal/model"
+)
+
+// GeneratedValue 通过 toyorm-gen 生成的 model.Accessor 读写字段，
+// 除了 Converter 和 SetField 以外不需要反射
+type GeneratedValue struct {
+	acc   model.Accessor
+	model *model.Model
+}
+
+// NewGeneratedValue 模型有生成的 Accessor 的时候返回 GeneratedValue，
+// 否则退化成 UnsafeValue
+func NewGeneratedValue(val any, m *model.Model) Value {
+	if m.Accessor {
+		return GeneratedValue{
+			acc:   val.(model.Accessor),
+			model: m,
+		}
+	}
+	return NewUnsafeValue(val, m)
+}
+
+func (g GeneratedValue) Field(index int) (any, error) {
+	if index < 0 || index >= len(g.model.Columns) {
+		return nil, errs.NewErrUnknownField("")
+	}
+	return g.field(g.model.Columns[index])
+}
+
+func (g GeneratedValue) FieldByName(name string) (any, error) {
+	fd, ok := g.model.FieldMap[name]
+	if !ok {
+		return nil, errs.NewErrUnknownField(name)
+	}
+	return g.field(fd)
+}
+
+func (g GeneratedValue) field(fd *model.Field) (any, error) {
+	if fd.Converter != nil {
+		return g.acc.ToyormField(fd.AccessorIndex), nil
+	}
+	info := typeInfoOf(fd.Type)
+	if !info.writable {
+		return nil, errs.NewErrUnsupportedFieldType(fd.Name, fd.Type)
+	}
+	if info.ptrValuer {
+		return g.acc.ToyormFieldPtr(fd.AccessorIndex), nil
+	}
+	return g.acc.ToyormField(fd.AccessorIndex), nil
+}
+
+func (g GeneratedValue) SetColumns(rows *sql.Rows) error {
+	cs, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if len(cs) > len(g.model.ColMap) {
+		return errs.ErrTooManyReturnedColumns
+	}
+
+	colValues := make([]any, len(cs))
+	converted := false
+	for i, c := range cs {
+		fd, ok := g.model.ColMap[c]
+		if !ok {
+			return errs.NewErrUnknownColumn(c)
+		}
+		if err = checkReadable(fd); err != nil {
+			return err
+		}
+		if fd.Converter != nil {
+			colValues[i] = new(any)
+			converted = true
+			continue
+		}
+		colValues[i] = g.acc.ToyormFieldPtr(fd.AccessorIndex)
+	}
+	if err = rows.Scan(colValues...); err != nil || !converted {
+		return err
+	}
+
+	for i, c := range cs {
+		fd := g.model.ColMap[c]
+		if fd.Converter == nil {
+			continue
+		}
+		ptr := reflect.ValueOf(g.acc.ToyormFieldPtr(fd.AccessorIndex))
+		if err = setConverted(fd, ptr, colValues[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g GeneratedValue) SetField(index int, val any) error {
+	if index < 0 || index >= len(g.model.Columns) {
+		return errs.NewErrUnknownField("")
+	}
+	fd := g.model.Columns[index]
+	ptr := reflect.ValueOf(g.acc.ToyormFieldPtr(fd.AccessorIndex))
+	return setValue(ptr.Elem(), fd, val)
+}
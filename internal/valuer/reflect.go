@@ -3,6 +3,7 @@ package valuer
 import (
 	"database/sql"
 	"reflect"
+	"unsafe"
 
 	"github.com/aristletl/toyorm/internal/errs"
 	"github.com/aristletl/toyorm/internal/model"
@@ -13,9 +14,22 @@ type ReflectValue struct {
 	model *model.Model
 }
 
+// field 返回字段的 reflect.Value。
+// 没有导出的字段不能通过 reflect 读写，和 UnsafeValue 一样通过地址访问
+func (r ReflectValue) field(fd *model.Field) reflect.Value {
+	res := r.val.FieldByIndex(fd.IndexPath)
+	if res.CanSet() {
+		return res
+	}
+	return reflect.NewAt(res.Type(), unsafe.Pointer(res.UnsafeAddr())).Elem()
+}
+
 func (r ReflectValue) Field(index int) (any, error) {
-	//TODO implement me
-	panic("implement me")
+	if index < 0 || index >= len(r.model.Columns) {
+		return nil, errs.NewErrUnknownField("")
+	}
+	fd := r.model.Columns[index]
+	return fieldValue(fd, r.field(fd).Addr())
 }
 
 func (r ReflectValue) FieldByName(name string) (any, error) {
@@ -23,8 +37,7 @@ func (r ReflectValue) FieldByName(name string) (any, error) {
 	if !ok {
 		return nil, errs.NewErrUnknownField(name)
 	}
-	res := r.val.Field(fd.Index)
-	return res.Interface(), nil
+	return fieldValue(fd, r.field(fd).Addr())
 }
 
 func (r ReflectValue) SetColumns(rows *sql.Rows) error {
@@ -38,15 +51,18 @@ func (r ReflectValue) SetColumns(rows *sql.Rows) error {
 	}
 
 	colValues := make([]any, len(cs))
-	colEleValues := make([]reflect.Value, len(cs))
+	colPtrs := make([]reflect.Value, len(cs))
 	for i, c := range cs {
 		fd, ok := r.model.ColMap[c]
 		if !ok {
 			return errs.NewErrUnknownColumn(c)
 		}
+		if err = checkReadable(fd); err != nil {
+			return err
+		}
 		val := reflect.New(fd.Type)
-		colValues[i] = val.Interface()
-		colEleValues[i] = val.Elem()
+		colValues[i] = scanTarget(fd, val)
+		colPtrs[i] = val
 	}
 	if err = rows.Scan(colValues...); err != nil {
 		return err
@@ -54,12 +70,22 @@ func (r ReflectValue) SetColumns(rows *sql.Rows) error {
 
 	for i, c := range cs {
 		cm := r.model.ColMap[c]
-		fd := r.val.Field(cm.Index)
-		fd.Set(colEleValues[i])
+		if err = setConverted(cm, colPtrs[i], colValues[i]); err != nil {
+			return err
+		}
+		r.field(cm).Set(colPtrs[i].Elem())
 	}
 	return nil
 }
 
+func (r ReflectValue) SetField(index int, val any) error {
+	if index < 0 || index >= len(r.model.Columns) {
+		return errs.NewErrUnknownField("")
+	}
+	fd := r.model.Columns[index]
+	return setValue(r.field(fd), fd, val)
+}
+
 func NewReflectValue(val any, m *model.Model) Value {
 	return ReflectValue{
 		val:   reflect.ValueOf(val).Elem(),
@@ -25,8 +25,8 @@ func (u UnsafeValue) Field(index int) (any, error) {
 	if index < 0 || index >= len(u.model.Columns) {
 		return nil, errs.NewErrUnknownField("")
 	}
-	val := reflect.NewAt(u.model.Columns[index].Type, unsafe.Pointer(uintptr(u.addr)+u.model.Columns[index].Offset)).Elem()
-	return val.Interface(), nil
+	fd := u.model.Columns[index]
+	return fieldValue(fd, reflect.NewAt(fd.Type, unsafe.Pointer(uintptr(u.addr)+fd.Offset)))
 }
 
 func (u UnsafeValue) FieldByName(name string) (any, error) {
@@ -34,8 +34,7 @@ func (u UnsafeValue) FieldByName(name string) (any, error) {
 	if !ok {
 		return nil, errs.NewErrUnknownField(name)
 	}
-	val := reflect.NewAt(field.Type, unsafe.Pointer(uintptr(u.addr)+field.Offset)).Elem()
-	return val.Interface(), nil
+	return fieldValue(field, reflect.NewAt(field.Type, unsafe.Pointer(uintptr(u.addr)+field.Offset)))
 }
 
 func (u UnsafeValue) SetColumns(rows *sql.Rows) error {
@@ -49,15 +48,37 @@ func (u UnsafeValue) SetColumns(rows *sql.Rows) error {
 	}
 
 	colValues := make([]any, len(cs))
+	fields := make([]reflect.Value, len(cs))
 	for i, c := range cs {
 		fd, ok := u.model.ColMap[c]
 		if !ok {
 			return errs.NewErrUnknownColumn(c)
 		}
+		if err = checkReadable(fd); err != nil {
+			return err
+		}
 
 		ptr := unsafe.Pointer(uintptr(u.addr) + fd.Offset)
-		val := reflect.NewAt(fd.Type, ptr)
-		colValues[i] = val.Interface()
+		fields[i] = reflect.NewAt(fd.Type, ptr)
+		colValues[i] = scanTarget(fd, fields[i])
+	}
+	if err = rows.Scan(colValues...); err != nil {
+		return err
+	}
+
+	for i, c := range cs {
+		if err = setConverted(u.model.ColMap[c], fields[i], colValues[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u UnsafeValue) SetField(index int, val any) error {
+	if index < 0 || index >= len(u.model.Columns) {
+		return errs.NewErrUnknownField("")
 	}
-	return rows.Scan(colValues...)
+	fd := u.model.Columns[index]
+	dst := reflect.NewAt(fd.Type, unsafe.Pointer(uintptr(u.addr)+fd.Offset)).Elem()
+	return setValue(dst, fd, val)
 }
@@ -2,6 +2,12 @@ package valuer
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aristletl/toyorm/internal/errs"
 
 	"github.com/aristletl/toyorm/internal/model"
 )
@@ -9,10 +15,167 @@ import (
 // Value 是对结构体实例的内部抽象
 type Value interface {
 	FieldByName(name string) (any, error)
+	// Field 返回下标为 index 的字段用于写入数据库的值。
+	// 一般就是字段本身的值，nil 指针返回 nil，
+	// 如果只有字段的指针实现了 driver.Valuer，返回字段的地址
 	Field(index int) (any, error)
 	// SetColumns 设置新值
 	SetColumns(rows *sql.Rows) error
+	// SetField 把 val 写入下标为 index 的字段，val 会被转换成字段的类型，
+	// 例如把自增 ID 写回结构体
+	SetField(index int, val any) error
 }
 
 // Creator 用于创建
 type Creator func(val interface{}, m *model.Model) Value
+
+// setValue 把 val 转换成 dst 的类型之后写入 dst，
+// dst 是指针的时候会分配新的值，例如把自增 ID 写入 *int64 字段
+func setValue(dst reflect.Value, fd *model.Field, val any) error {
+	v := reflect.ValueOf(val)
+	if !v.IsValid() {
+		return errs.NewErrUnsupportedFieldValue(fd.Name, val)
+	}
+	if v.Type().ConvertibleTo(dst.Type()) {
+		dst.Set(v.Convert(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Pointer && v.Type().ConvertibleTo(dst.Type().Elem()) {
+		ptr := reflect.New(dst.Type().Elem())
+		ptr.Elem().Set(v.Convert(dst.Type().Elem()))
+		dst.Set(ptr)
+		return nil
+	}
+	return errs.NewErrUnsupportedFieldValue(fd.Name, val)
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// typeInfo 记录一个字段类型怎么和数据库交互
+type typeInfo struct {
+	// readable 能不能从查询结果中扫描
+	readable bool
+	// writable 能不能作为参数写入数据库
+	writable bool
+	// ptrValuer 只有指针实现了 driver.Valuer，写入的时候要传地址
+	ptrValuer bool
+}
+
+// typeInfos 缓存 reflect.Type 到 typeInfo
+var typeInfos sync.Map
+
+func typeInfoOf(typ reflect.Type) typeInfo {
+	if info, ok := typeInfos.Load(typ); ok {
+		return info.(typeInfo)
+	}
+	info := typeInfo{
+		readable: readable(typ),
+		writable: writable(typ),
+	}
+	if !typ.Implements(valuerType) && typ.Kind() != reflect.Pointer {
+		info.ptrValuer = reflect.PointerTo(typ).Implements(valuerType)
+	}
+	typeInfos.Store(typ, info)
+	return info
+}
+
+// readable 判断 database/sql 能不能扫描到 typ 类型的字段里。
+// 指针字段对应可以为 NULL 的列，NULL 扫描成 nil
+func readable(typ reflect.Type) bool {
+	if reflect.PointerTo(typ).Implements(scannerType) {
+		return true
+	}
+	if typ.Kind() == reflect.Pointer {
+		return readable(typ.Elem())
+	}
+	return basicType(typ)
+}
+
+// writable 判断 typ 类型的字段能不能作为参数写入数据库，
+// nil 指针写入 NULL
+func writable(typ reflect.Type) bool {
+	if typ.Implements(valuerType) || reflect.PointerTo(typ).Implements(valuerType) {
+		return true
+	}
+	if typ.Kind() == reflect.Pointer {
+		return writable(typ.Elem())
+	}
+	return basicType(typ)
+}
+
+func basicType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() == reflect.Uint8
+	case reflect.Struct:
+		return typ == timeType
+	}
+	return false
+}
+
+// fieldValue 返回字段 fd 用于写入数据库的值，ptr 是指向字段的指针
+func fieldValue(fd *model.Field, ptr reflect.Value) (any, error) {
+	info := typeInfoOf(fd.Type)
+	if fd.Converter != nil {
+		// 注册了 Converter 的字段返回原本的值，绑定参数的时候再转换
+		info = typeInfo{writable: true}
+	}
+	if !info.writable {
+		return nil, errs.NewErrUnsupportedFieldType(fd.Name, fd.Type)
+	}
+	if info.ptrValuer {
+		return ptr.Interface(), nil
+	}
+	// nil 指针直接写入 NULL，不然指针接收器的 driver.Valuer 会在 nil 上被调用
+	if val := ptr.Elem(); fd.Type.Kind() == reflect.Pointer && val.IsNil() {
+		return nil, nil
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// checkReadable 检查查询结果能不能扫描到字段 fd
+func checkReadable(fd *model.Field) error {
+	if fd.Converter == nil && !typeInfoOf(fd.Type).readable {
+		return errs.NewErrUnsupportedFieldType(fd.Name, fd.Type)
+	}
+	return nil
+}
+
+// scanTarget 返回扫描字段 fd 的目标，ptr 是指向字段的指针。
+// 注册了 Converter 的字段先扫描到 any 里面，再通过 setConverted 写入字段
+func scanTarget(fd *model.Field, ptr reflect.Value) any {
+	if fd.Converter != nil {
+		return new(any)
+	}
+	return ptr.Interface()
+}
+
+// setConverted 用 Converter 转换扫描出来的值，写入 ptr 指向的字段
+func setConverted(fd *model.Field, ptr reflect.Value, target any) error {
+	if fd.Converter == nil {
+		return nil
+	}
+	src := *(target.(*any))
+	if fd.Type.Kind() != reflect.Pointer {
+		return fd.Converter.FromDB(src, ptr.Interface())
+	}
+	if src == nil {
+		ptr.Elem().Set(reflect.Zero(fd.Type))
+		return nil
+	}
+	val := reflect.New(fd.Type.Elem())
+	if err := fd.Converter.FromDB(src, val.Interface()); err != nil {
+		return err
+	}
+	ptr.Elem().Set(val)
+	return nil
+}
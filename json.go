@@ -0,0 +1,165 @@
+package toyorm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+// JSONColumn 以 JSON 的形式存储 Val，写入的时候序列化，读取的时候反序列化。
+// Valid 为 false 的时候代表 NULL
+type JSONColumn[T any] struct {
+	Val   T
+	Valid bool
+}
+
+// Value 返回 JSON 字符串，sqlite 会把 BLOB 当成 JSONB，所以不能返回 []byte
+func (j JSONColumn[T]) Value() (driver.Value, error) {
+	if !j.Valid {
+		return nil, nil
+	}
+	bs, err := json.Marshal(j.Val)
+	if err != nil {
+		return nil, err
+	}
+	return string(bs), nil
+}
+
+func (j *JSONColumn[T]) Scan(src any) error {
+	var bs []byte
+	switch data := src.(type) {
+	case nil:
+		var t T
+		j.Val, j.Valid = t, false
+		return nil
+	case string:
+		bs = []byte(data)
+	case []byte:
+		bs = data
+	default:
+		return errs.NewErrInvalidJSONSource(src)
+	}
+	var t T
+	if err := json.Unmarshal(bs, &t); err != nil {
+		return err
+	}
+	j.Val, j.Valid = t, true
+	return nil
+}
+
+// JSONPathExpr JSON 列中 path 对应的值，
+// 例如 Col("Settings").JSONPath("$.theme")，可以用在 Select、Where 以及 Assign 里面
+type JSONPathExpr struct {
+	col   Column
+	path  string
+	alias string
+}
+
+// JSONPath 取出 JSON 列中 path 对应的值，path 是 $.a.b 或者 $[0] 的形式
+func (c Column) JSONPath(path string) JSONPathExpr {
+	return JSONPathExpr{
+		col:  c,
+		path: path,
+	}
+}
+
+func (j JSONPathExpr) Expr() {}
+
+func (j JSONPathExpr) selectable() {}
+
+func (j JSONPathExpr) AS(alias string) JSONPathExpr {
+	return JSONPathExpr{
+		col:   j.col,
+		path:  j.path,
+		alias: alias,
+	}
+}
+
+func (j JSONPathExpr) EQ(val any) Predicate {
+	return Predicate{
+		left:  j,
+		op:    opEQ,
+		right: valueOf(val),
+	}
+}
+
+func (j JSONPathExpr) GT(val any) Predicate {
+	return Predicate{
+		left:  j,
+		op:    opGT,
+		right: valueOf(val),
+	}
+}
+
+func (j JSONPathExpr) LT(val any) Predicate {
+	return Predicate{
+		left:  j,
+		op:    opLT,
+		right: valueOf(val),
+	}
+}
+
+func (j JSONPathExpr) In(vals ...any) Predicate {
+	return Predicate{
+		left:  j,
+		op:    opIN,
+		right: valueList{vals: vals},
+	}
+}
+
+// IsNull path 不存在或者对应的值是 null
+func (j JSONPathExpr) IsNull() Predicate {
+	return Predicate{
+		left: j,
+		op:   opIsNull,
+	}
+}
+
+func (j JSONPathExpr) IsNotNull() Predicate {
+	return Predicate{
+		left: j,
+		op:   opIsNotNull,
+	}
+}
+
+func (j JSONPathExpr) Asc() OrderBy {
+	return OrderBy{
+		expr:  j,
+		order: "ASC",
+	}
+}
+
+func (j JSONPathExpr) Desc() OrderBy {
+	return OrderBy{
+		expr:  j,
+		order: "DESC",
+	}
+}
+
+// buildJSONPath 校验 path 之后交给方言构造，path 作为参数传递
+func (s *SQLBuilder) buildJSONPath(j JSONPathExpr, useAlias bool) error {
+	if !strings.HasPrefix(j.path, "$") {
+		return errs.NewErrInvalidJSONPath(j.path)
+	}
+	if err := s.dialect.BuildJSONPath(s, j); err != nil {
+		return err
+	}
+	if useAlias {
+		s.As(j.alias)
+	}
+	return nil
+}
+
+// buildJSONExtract 构造 fn(`col`, ?)
+func (s *SQLBuilder) buildJSONExtract(fn string, j JSONPathExpr) error {
+	s.builder.WriteString(fn)
+	s.builder.WriteString("(")
+	if err := s.buildTableColumn(j.col); err != nil {
+		return err
+	}
+	s.builder.WriteString(", ?)")
+	s.AddArgs(j.path)
+	return nil
+}
@@ -0,0 +1,200 @@
+package toyorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+func TestJSONPath_Build(t *testing.T) {
+	db := memoryDB(t)
+	sqliteDB := memoryDB(t, DBWithDialect(SQLite))
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "where",
+			q:    NewSelector[Profile](db).Where(Col("Settings").JSONPath("$.theme").EQ("dark")),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `profile` WHERE JSON_UNQUOTE(JSON_EXTRACT(`settings`, ?)) = ?;",
+				Args: []any{"$.theme", "dark"},
+			},
+		},
+		{
+			name: "sqlite where",
+			q: NewSelector[Profile](sqliteDB).Where(Col("Settings").JSONPath("$.font.size").GT(12).
+				AND(Col("Settings").JSONPath("$.tags[0]").IsNotNull())),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `profile` WHERE (json_extract(`settings`, ?) > ?) AND (json_extract(`settings`, ?) IS NOT NULL);",
+				Args: []any{"$.font.size", 12, "$.tags[0]"},
+			},
+		},
+		{
+			name: "select",
+			q: NewSelector[Profile](db).Select(Col("Id"), Col("Settings").JSONPath("$.theme").AS("theme")).
+				OrderBy(Alias("theme").Asc(), Col("Settings").JSONPath("$.font.size").Desc()),
+			wantQuery: &Query{
+				SQL: "SELECT `id`, JSON_UNQUOTE(JSON_EXTRACT(`settings`, ?)) AS `theme` FROM `profile` " +
+					"ORDER BY `theme` ASC, JSON_UNQUOTE(JSON_EXTRACT(`settings`, ?)) DESC;",
+				Args: []any{"$.theme", "$.font.size"},
+			},
+		},
+		{
+			name: "in",
+			q:    NewSelector[Profile](db).Where(Col("Settings").JSONPath("$.theme").In("dark", "light")),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `profile` WHERE JSON_UNQUOTE(JSON_EXTRACT(`settings`, ?)) IN (?, ?);",
+				Args: []any{"$.theme", "dark", "light"},
+			},
+		},
+		{
+			// 字符串不带引号，和 sqlite 一样
+			name: "mysql assign",
+			q: NewUpdater[Profile](db).Set(Assign("Theme", Col("Settings").JSONPath("$.theme"))).
+				Where(Col("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `profile` SET `theme`=JSON_UNQUOTE(JSON_EXTRACT(`settings`, ?)) WHERE `id` = ?;",
+				Args: []any{"$.theme", 1},
+			},
+		},
+		{
+			name: "sqlite assign",
+			q: NewUpdater[Profile](sqliteDB).Set(Assign("Theme", Col("Settings").JSONPath("$.theme"))).
+				Where(Col("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `profile` SET `theme`=json_extract(`settings`, ?) WHERE `id` = ?;",
+				Args: []any{"$.theme", 1},
+			},
+		},
+		{
+			name: "insert",
+			q: NewInserter[Profile](db).Values(&Profile{
+				Id:       1,
+				Settings: JSONColumn[Settings]{Val: Settings{Theme: "dark"}, Valid: true},
+			}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `profile`(`id`, `settings`, `theme`) VALUES(?, ?, ?);",
+				Args: []any{int64(1), JSONColumn[Settings]{Val: Settings{Theme: "dark"}, Valid: true}, ""},
+			},
+		},
+		{
+			name:    "invalid path",
+			q:       NewSelector[Profile](db).Where(Col("Settings").JSONPath("theme").EQ("dark")),
+			wantErr: errs.NewErrInvalidJSONPath("theme"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestJSONColumn(t *testing.T) {
+	testCases := []struct {
+		name    string
+		src     any
+		want    JSONColumn[Settings]
+		wantErr error
+	}{
+		{
+			name: "string",
+			src:  `{"theme":"dark","font":{"size":14}}`,
+			want: JSONColumn[Settings]{Val: Settings{Theme: "dark", Font: Font{Size: 14}}, Valid: true},
+		},
+		{
+			name: "bytes",
+			src:  []byte(`{"tags":["a"]}`),
+			want: JSONColumn[Settings]{Val: Settings{Tags: []string{"a"}}, Valid: true},
+		},
+		{
+			name: "null",
+			src:  nil,
+			want: JSONColumn[Settings]{},
+		},
+		{
+			name:    "invalid",
+			src:     12,
+			wantErr: errs.NewErrInvalidJSONSource(12),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			j := JSONColumn[Settings]{Val: Settings{Theme: "old"}, Valid: true}
+			err := j.Scan(tc.src)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.want, j)
+		})
+	}
+
+	val, err := JSONColumn[Settings]{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, val)
+	val, err = JSONColumn[[]int]{Val: []int{1, 2}, Valid: true}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2]", val)
+}
+
+func TestJSONPath_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:json.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE profile(id INTEGER PRIMARY KEY, settings TEXT, theme TEXT);")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	profiles := []*Profile{
+		{Id: 1, Settings: JSONColumn[Settings]{Val: Settings{Theme: "dark", Font: Font{Size: 14}}, Valid: true}},
+		{Id: 2, Settings: JSONColumn[Settings]{Val: Settings{Theme: "light", Font: Font{Size: 12}}, Valid: true}},
+		{Id: 3},
+	}
+	require.NoError(t, NewInserter[Profile](db).Values(profiles...).Exec(ctx).Err())
+
+	got, err := NewSelector[Profile](db).Where(Col("Settings").JSONPath("$.theme").EQ("dark")).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, profiles[:1], got)
+
+	require.NoError(t, NewUpdater[Profile](db).Set(Assign("Theme", Col("Settings").JSONPath("$.theme"))).
+		Where(Col("Settings").JSONPath("$.font.size").GT(12)).Exec(ctx).Err())
+	got, err = NewSelector[Profile](db).Where(Col("Theme").EQ("dark")).GetMulti(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(1), got[0].Id)
+
+	got, err = NewSelector[Profile](db).Where(Col("Settings").IsNull()).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, profiles[2:], got)
+}
+
+type Font struct {
+	Size int `json:"size,omitempty"`
+}
+
+type Settings struct {
+	Theme string   `json:"theme,omitempty"`
+	Font  Font     `json:"font"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type Profile struct {
+	Id       int64
+	Settings JSONColumn[Settings]
+	Theme    string
+}
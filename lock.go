@@ -0,0 +1,23 @@
+package toyorm
+
+type lockMode string
+
+const (
+	lockForUpdate lockMode = "FOR UPDATE"
+	lockForShare  lockMode = "FOR SHARE"
+)
+
+type lockWait string
+
+const (
+	lockWaitDefault lockWait = ""
+	lockNoWait      lockWait = "NOWAIT"
+	lockSkipLocked  lockWait = "SKIP LOCKED"
+)
+
+// Lock 代表 SELECT 语句末尾的行锁子句，
+// 例如 FOR UPDATE SKIP LOCKED，具体怎么渲染交给 Dialect
+type Lock struct {
+	mode lockMode
+	wait lockWait
+}
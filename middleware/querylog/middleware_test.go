@@ -2,18 +2,32 @@ package querylog
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
+	"testing"
+
 	"github.com/aristletl/toyorm"
 	_ "github.com/mattn/go-sqlite3"
-	"testing"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMiddlewareBuilder_Build(t *testing.T) {
+	const dsn = "file:querylog.db?cache=shared&mode=memory"
+	sqlDB, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sqlDB.Close() }()
+	_, err = sqlDB.Exec("CREATE TABLE test_model(id INTEGER);INSERT INTO test_model VALUES (1);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logged string
 	builder := MiddlewareBuilder{}
 	builder.LogFunc(func(sql string, args ...any) {
-		fmt.Println(sql)
+		logged = sql
 	})
-	db, err := toyorm.Open("sqlite3", "file:test.db?cache=shared&mode=memory", toyorm.DBWithMiddlewares(builder.Build()))
+	db, err := toyorm.Open("sqlite3", dsn, toyorm.DBWithMiddlewares(builder.Build()))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -22,7 +36,9 @@ func TestMiddlewareBuilder_Build(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(t, "SELECT * FROM `test_model`;", logged)
 }
 
 type TestModel struct {
+	Id int64
 }
@@ -0,0 +1,172 @@
+package toyorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+// Null 可以为 NULL 的 T，Valid 为 false 的时候代表 NULL，
+// 用来代替 sql.NullString、sql.NullInt64 这些类型，例如 Null[time.Time]。
+// 作为参数的时候 EQ(Null[T]{}) 会变成 IS NULL
+type Null[T any] struct {
+	Val   T
+	Valid bool
+}
+
+// NullOf 返回一个不是 NULL 的 Null[T]
+func NullOf[T any](val T) Null[T] {
+	return Null[T]{Val: val, Valid: true}
+}
+
+// ValueType 返回 T 的类型，Registry 用来校验字段的类型，例如软删除的字段
+func (n Null[T]) ValueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (n Null[T]) null() bool {
+	return !n.Valid
+}
+
+// Value T 或者 *T 实现了 driver.Valuer 的时候使用 T 的实现，
+// 否则和 database/sql 一样按照底层类型转换
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	if v, ok := any(n.Val).(driver.Valuer); ok {
+		return v.Value()
+	}
+	if v, ok := any(&n.Val).(driver.Valuer); ok {
+		return v.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(n.Val)
+}
+
+// Scan *T 实现了 sql.Scanner 的时候使用 T 的实现，
+// 否则在数字、布尔值和字符串之间转换
+func (n *Null[T]) Scan(src any) error {
+	var t T
+	if src == nil {
+		n.Val, n.Valid = t, false
+		return nil
+	}
+	if s, ok := any(&t).(sql.Scanner); ok {
+		if err := s.Scan(src); err != nil {
+			return err
+		}
+	} else if err := convertScanned(src, reflect.ValueOf(&t).Elem()); err != nil {
+		return err
+	}
+	n.Val, n.Valid = t, true
+	return nil
+}
+
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Val)
+}
+
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	var t T
+	if string(data) == "null" {
+		n.Val, n.Valid = t, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	n.Val, n.Valid = t, true
+	return nil
+}
+
+// nullValue 由 Null[T] 实现，用于把 EQ(Null[T]{}) 构造成 IS NULL
+type nullValue interface {
+	null() bool
+}
+
+// isNull 判断表达式是不是 NULL 的 Null[T] 参数
+func isNull(e Expression) bool {
+	v, ok := e.(Value)
+	if !ok {
+		return false
+	}
+	n, ok := v.val.(nullValue)
+	return ok && n.null()
+}
+
+// convertScanned 把驱动返回的 src 写入 dst，
+// 支持可以直接赋值的类型、数字之间以及字符串和数字、布尔值之间的转换
+func convertScanned(src any, dst reflect.Value) error {
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		if bs, ok := src.([]byte); ok {
+			// 驱动可能会复用 []byte
+			src = append([]byte(nil), bs...)
+			sv = reflect.ValueOf(src)
+		}
+		dst.Set(sv)
+		return nil
+	}
+
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		if isNumberKind(sv.Kind()) && isNumberKind(dst.Kind()) ||
+			sv.Kind() == reflect.Bool && dst.Kind() == reflect.Bool {
+			dst.Set(sv.Convert(dst.Type()))
+			return nil
+		}
+		return errs.NewErrUnsupportedScanSource(src, dst.Type())
+	}
+
+	var err error
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(text)
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() != reflect.Uint8 {
+			return errs.NewErrUnsupportedScanSource(src, dst.Type())
+		}
+		dst.SetBytes([]byte(text))
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(text)
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(text, 10, dst.Type().Bits())
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(text, 10, dst.Type().Bits())
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(text, dst.Type().Bits())
+		dst.SetFloat(f)
+	default:
+		return errs.NewErrUnsupportedScanSource(src, dst.Type())
+	}
+	return err
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package toyorm
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+func TestNull_Scan(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name    string
+		dst     interface{ Scan(any) error }
+		src     any
+		want    any
+		wantErr error
+	}{
+		{
+			name: "int64 to int",
+			dst:  &Null[int]{},
+			src:  int64(12),
+			want: &Null[int]{Val: 12, Valid: true},
+		},
+		{
+			name: "bytes to int",
+			dst:  &Null[int]{},
+			src:  []byte("12"),
+			want: &Null[int]{Val: 12, Valid: true},
+		},
+		{
+			name: "bytes to string",
+			dst:  &Null[string]{},
+			src:  []byte("tom"),
+			want: &Null[string]{Val: "tom", Valid: true},
+		},
+		{
+			name: "string to bool",
+			dst:  &Null[bool]{},
+			src:  "true",
+			want: &Null[bool]{Val: true, Valid: true},
+		},
+		{
+			name: "float to float32",
+			dst:  &Null[float32]{},
+			src:  1.5,
+			want: &Null[float32]{Val: 1.5, Valid: true},
+		},
+		{
+			name: "time",
+			dst:  &Null[time.Time]{},
+			src:  now,
+			want: &Null[time.Time]{Val: now, Valid: true},
+		},
+		{
+			name: "scanner",
+			dst:  &Null[Money]{},
+			src:  "1.50",
+			want: &Null[Money]{Val: 150, Valid: true},
+		},
+		{
+			name: "null",
+			dst:  &Null[int]{Val: 12, Valid: true},
+			src:  nil,
+			want: &Null[int]{},
+		},
+		{
+			name:    "invalid",
+			dst:     &Null[time.Time]{},
+			src:     int64(12),
+			wantErr: errs.NewErrUnsupportedScanSource(int64(12), reflect.TypeOf(time.Time{})),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dst.Scan(tc.src)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.want, tc.dst)
+		})
+	}
+}
+
+func TestNull_Value(t *testing.T) {
+	val, err := Null[int]{Val: 12}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, val)
+	val, err = NullOf(12).Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), val)
+	val, err = NullOf(Money(150)).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "1.50", val)
+}
+
+func TestNull_JSON(t *testing.T) {
+	type user struct {
+		Name Null[string] `json:"name"`
+		Age  Null[int]    `json:"age"`
+	}
+	bs, err := json.Marshal(user{Name: NullOf("tom")})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"tom","age":null}`, string(bs))
+
+	var u user
+	require.NoError(t, json.Unmarshal([]byte(`{"name":null,"age":18}`), &u))
+	assert.Equal(t, user{Age: NullOf(18)}, u)
+}
+
+func TestNull_Build(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	db := memoryDB(t, DBWithClock(func() time.Time { return now }))
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "eq null",
+			q:    NewSelector[NullModel](db).Where(Col("Nickname").EQ(Null[string]{})),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `null_model` WHERE (`nickname` IS NULL) AND (`deleted_at` IS NULL);",
+			},
+		},
+		{
+			name: "eq valid",
+			q:    NewSelector[NullModel](db).Where(Col("Nickname").EQ(NullOf("tom"))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `null_model` WHERE (`nickname` = ?) AND (`deleted_at` IS NULL);",
+				Args: []any{NullOf("tom")},
+			},
+		},
+		{
+			// 自动写入时间
+			name: "insert",
+			q:    NewInserter[NullModel](db).Values(&NullModel{Id: 1}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `null_model`(`id`, `nickname`, `created_at`, `deleted_at`) VALUES(?, ?, ?, ?);",
+				Args: []any{int64(1), Null[string]{}, NullOf(now), Null[time.Time]{}},
+			},
+		},
+		{
+			name: "soft delete",
+			q:    NewDeleter[NullModel](db).Where(Col("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `null_model` SET `deleted_at`=? WHERE (`id` = ?) AND (`deleted_at` IS NULL);",
+				Args: []any{now, 1},
+			},
+		},
+		{
+			name:    "invalid soft delete",
+			q:       NewSelector[InvalidNullSoftModel](db),
+			wantErr: errs.NewErrInvalidSoftDeleteType("DeletedAt"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestNull_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:null.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE null_model(id INTEGER PRIMARY KEY, nickname TEXT, " +
+		"created_at DATETIME, deleted_at DATETIME);")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, NewInserter[NullModel](db).Values(
+		&NullModel{Id: 1, Nickname: NullOf("tom")}, &NullModel{Id: 2}).Exec(ctx).Err())
+
+	got, err := NewSelector[NullModel](db).Where(Col("Nickname").EQ(Null[string]{})).GetMulti(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(2), got[0].Id)
+	assert.True(t, got[0].CreatedAt.Valid)
+
+	require.NoError(t, NewDeleter[NullModel](db).Where(Col("Id").EQ(1)).Exec(ctx).Err())
+	got, err = NewSelector[NullModel](db).Unscoped().Where(Col("Id").EQ(1)).GetMulti(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, NullOf("tom"), got[0].Nickname)
+	assert.True(t, got[0].DeletedAt.Valid)
+}
+
+type NullModel struct {
+	Id        int64
+	Nickname  Null[string]
+	CreatedAt Null[time.Time] `orm:"autoCreateTime"`
+	DeletedAt Null[time.Time] `orm:"softDelete"`
+}
+
+type InvalidNullSoftModel struct {
+	Id        int64
+	DeletedAt Null[int64] `orm:"softDelete"`
+}
@@ -11,6 +11,10 @@ const (
 	opLT  = "<"
 	opGT  = ">"
 	opADD = "+"
+	opIN  = "IN"
+
+	opIsNull    = "IS NULL"
+	opIsNotNull = "IS NOT NULL"
 
 	opNOT = "NOT"
 	opAND = "AND"
@@ -32,6 +36,21 @@ type Value struct {
 
 func (v Value) Expr() {}
 
+// valueList 代表 IN 后面的参数列表
+type valueList struct {
+	vals []any
+}
+
+func (v valueList) Expr() {}
+
+// valueOf 表达式原样返回，例如 Col("A").EQ(Col("B"))，其它的作为参数
+func valueOf(val any) Expression {
+	if expr, ok := val.(Expression); ok {
+		return expr
+	}
+	return Value{val: val}
+}
+
 func (p Predicate) AND(p1 Predicate) Predicate {
 	return Predicate{
 		left:  p,
@@ -0,0 +1,191 @@
+package toyorm
+
+import (
+	"context"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/aristletl/toyorm/internal/model"
+	"github.com/aristletl/toyorm/internal/valuer"
+)
+
+// Repository 封装了基于主键的常用操作，
+// 内部使用 Selector、Inserter、Updater 和 Deleter，所以同样会经过中间件。
+// 复合主键的值按照主键字段定义的顺序传入
+type Repository[T any] struct {
+	sess       Session
+	valCreator valuer.Creator
+}
+
+func NewRepository[T any](sess Session) *Repository[T] {
+	return &Repository[T]{
+		sess:       sess,
+		valCreator: sess.getCore().valCreator,
+	}
+}
+
+// FindByPK 例如 FindByPK(ctx, 1)，复合主键 FindByPK(ctx, userId, orderId)
+func (r *Repository[T]) FindByPK(ctx context.Context, pk ...any) (*T, error) {
+	m, err := r.model()
+	if err != nil {
+		return nil, err
+	}
+	p, err := pkPredicate(m, pk)
+	if err != nil {
+		return nil, err
+	}
+	return NewSelector[T](r.sess).Where(p).Get(ctx)
+}
+
+// FindByPKs 单一主键传入主键的值，复合主键每一个元素都是一个 []any，
+// 例如 FindByPKs(ctx, []any{1, 2}, []any{1, 3})
+func (r *Repository[T]) FindByPKs(ctx context.Context, pks ...any) ([]*T, error) {
+	if len(pks) == 0 {
+		return []*T{}, nil
+	}
+	m, err := r.model()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.PrimaryKeys) == 0 {
+		return nil, errs.ErrNoPrimaryKey
+	}
+
+	var p Predicate
+	if len(m.PrimaryKeys) == 1 {
+		p = Col(m.PrimaryKeys[0].Name).In(pks...)
+	} else {
+		for i, pk := range pks {
+			vals, ok := pk.([]any)
+			if !ok {
+				return nil, errs.NewErrPrimaryKeyCount(len(m.PrimaryKeys), 1)
+			}
+			cur, err := pkPredicate(m, vals)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				p = cur
+			} else {
+				p = p.OR(cur)
+			}
+		}
+	}
+	return NewSelector[T](r.sess).Where(p).GetMulti(ctx)
+}
+
+// Save 主键都是零值的时候插入，否则插入或者更新所有非主键的列。
+// 有版本号的模型主键不是零值的时候使用 Updater 更新，检查并且增加版本号，
+// 这时候数据不存在会返回 errs.ErrStaleObject，插入新的数据需要使用 Inserter
+func (r *Repository[T]) Save(ctx context.Context, t *T) Result {
+	m, err := r.model()
+	if err != nil {
+		return Result{err: err}
+	}
+	if len(m.PrimaryKeys) == 0 {
+		return Result{err: errs.ErrNoPrimaryKey}
+	}
+	pks, err := r.pkValues(m, t)
+	if err != nil {
+		return Result{err: err}
+	}
+
+	i := NewInserter[T](r.sess).Values(t)
+	for idx, pk := range pks {
+		if isZero(m.PrimaryKeys[idx], pk) {
+			continue
+		}
+		// upsert 会直接覆盖掉版本号，绕过了乐观锁
+		if m.Version != nil {
+			return NewUpdater[T](r.sess).Update(t).Exec(ctx)
+		}
+		return i.Upsert().Update(r.nonPKColumns(m)...).Exec(ctx)
+	}
+	return i.Exec(ctx)
+}
+
+// UpdateByPK 按照 t 的主键更新，fields 为空的时候更新所有非主键的字段
+func (r *Repository[T]) UpdateByPK(ctx context.Context, t *T, fields ...string) Result {
+	m, err := r.model()
+	if err != nil {
+		return Result{err: err}
+	}
+	pks, err := r.pkValues(m, t)
+	if err != nil {
+		return Result{err: err}
+	}
+	p, err := pkPredicate(m, pks)
+	if err != nil {
+		return Result{err: err}
+	}
+
+	cols := make([]Assignable, 0, len(fields))
+	for _, fd := range fields {
+		cols = append(cols, Col(fd))
+	}
+	return NewUpdater[T](r.sess).Update(t).Set(cols...).Where(p).Exec(ctx)
+}
+
+func (r *Repository[T]) DeleteByPK(ctx context.Context, pk ...any) Result {
+	m, err := r.model()
+	if err != nil {
+		return Result{err: err}
+	}
+	p, err := pkPredicate(m, pk)
+	if err != nil {
+		return Result{err: err}
+	}
+	return NewDeleter[T](r.sess).Where(p).Exec(ctx)
+}
+
+func (r *Repository[T]) model() (*model.Model, error) {
+	var t T
+	return r.sess.getCore().r.Get(&t)
+}
+
+func (r *Repository[T]) pkValues(m *model.Model, t *T) ([]any, error) {
+	if len(m.PrimaryKeys) == 0 {
+		return nil, errs.ErrNoPrimaryKey
+	}
+	val := r.valCreator(t, m)
+	res := make([]any, 0, len(m.PrimaryKeys))
+	for _, pk := range m.PrimaryKeys {
+		v, err := val.Field(pk.Index)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// nonPKColumns 不包括 autoCreateTime 字段，避免 upsert 的时候覆盖创建时间。
+// 所有列都是主键的时候返回主键，保证 upsert 语句合法
+func (r *Repository[T]) nonPKColumns(m *model.Model) []Assignable {
+	res := make([]Assignable, 0, len(m.Columns))
+	for _, fd := range m.Columns {
+		if !fd.PrimaryKey && !fd.AutoCreateTime {
+			res = append(res, Col(fd.Name))
+		}
+	}
+	if len(res) == 0 {
+		for _, pk := range m.PrimaryKeys {
+			res = append(res, Col(pk.Name))
+		}
+	}
+	return res
+}
+
+// pkPredicate 构造 `pk1` = ? AND `pk2` = ?
+func pkPredicate(m *model.Model, vals []any) (Predicate, error) {
+	if len(m.PrimaryKeys) == 0 {
+		return Predicate{}, errs.ErrNoPrimaryKey
+	}
+	if len(vals) != len(m.PrimaryKeys) {
+		return Predicate{}, errs.NewErrPrimaryKeyCount(len(m.PrimaryKeys), len(vals))
+	}
+	p := Col(m.PrimaryKeys[0].Name).EQ(vals[0])
+	for i := 1; i < len(vals); i++ {
+		p = p.AND(Col(m.PrimaryKeys[i].Name).EQ(vals[i]))
+	}
+	return p, nil
+}
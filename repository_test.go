@@ -0,0 +1,150 @@
+package toyorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	var stmts []string
+	ms := func(next Handler) Handler {
+		return func(ctx context.Context, qc *QueryContext) *QueryResult {
+			stmts = append(stmts, qc.Type)
+			return next(ctx, qc)
+		}
+	}
+	db, err := Open("sqlite3", "file:repository.db?cache=shared&mode=memory",
+		DBWithDialect(SQLite), DBWithMiddlewares(ms))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE test_model(id INTEGER PRIMARY KEY, first_name TEXT, age INTEGER, last_name TEXT);" +
+		"CREATE TABLE order_item(order_id INTEGER, item_id INTEGER, qty INTEGER, PRIMARY KEY(order_id, item_id));")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	repo := NewRepository[TestModel](db)
+
+	// 主键有值，插入
+	require.NoError(t, repo.Save(ctx, &TestModel{Id: 1, FirstName: "Tom", Age: 18}).Err())
+	require.NoError(t, repo.Save(ctx, &TestModel{Id: 2, FirstName: "Jerry", Age: 20}).Err())
+	// 主键冲突，更新
+	require.NoError(t, repo.Save(ctx, &TestModel{Id: 1, FirstName: "Tommy", Age: 19}).Err())
+
+	tm, err := repo.FindByPK(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &TestModel{Id: 1, FirstName: "Tommy", Age: 19}, tm)
+
+	tms, err := repo.FindByPKs(ctx, 1, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(tms))
+
+	affected, err := repo.UpdateByPK(ctx, &TestModel{Id: 2, FirstName: "Mouse", Age: 30}, "Age").RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	tm, err = repo.FindByPK(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, &TestModel{Id: 2, FirstName: "Jerry", Age: 30}, tm)
+
+	affected, err = repo.DeleteByPK(ctx, 2).RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	_, err = repo.FindByPK(ctx, 2)
+	assert.Equal(t, errs.ErrNoRows, err)
+
+	_, err = repo.FindByPK(ctx, 1, 2)
+	assert.Equal(t, errs.NewErrPrimaryKeyCount(1, 2), err)
+
+	// 复合主键
+	items := NewRepository[OrderItem](db)
+	require.NoError(t, items.Save(ctx, &OrderItem{OrderId: 1, ItemId: 1, Qty: 1}).Err())
+	require.NoError(t, items.Save(ctx, &OrderItem{OrderId: 1, ItemId: 2, Qty: 2}).Err())
+	require.NoError(t, items.Save(ctx, &OrderItem{OrderId: 1, ItemId: 2, Qty: 5}).Err())
+	require.NoError(t, items.UpdateByPK(ctx, &OrderItem{OrderId: 1, ItemId: 1, Qty: 3}).Err())
+
+	item, err := items.FindByPK(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, &OrderItem{OrderId: 1, ItemId: 2, Qty: 5}, item)
+
+	list, err := items.FindByPKs(ctx, []any{1, 1}, []any{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []*OrderItem{
+		{OrderId: 1, ItemId: 1, Qty: 3},
+		{OrderId: 1, ItemId: 2, Qty: 5},
+	}, list)
+
+	require.NoError(t, items.DeleteByPK(ctx, 1, 1).Err())
+	list, err = items.FindByPKs(ctx, []any{1, 1}, []any{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(list))
+
+	// 所有语句都经过了中间件
+	assert.Equal(t, []string{SQLInsert, SQLInsert, SQLInsert, SQLSelect, SQLSelect, SQLUpdate, SQLSelect,
+		SQLDelete, SQLSelect}, stmts[:9])
+}
+
+func TestRepository_SavePointerPK(t *testing.T) {
+	db, err := Open("sqlite3", "file:repository_ptr.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE coupon(code TEXT PRIMARY KEY, name TEXT);")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	repo := NewRepository[Coupon](db)
+	// nil 指针的主键是零值，插入
+	require.NoError(t, repo.Save(ctx, &Coupon{Name: "anonymous"}).Err())
+
+	code := "new_user"
+	require.NoError(t, repo.Save(ctx, &Coupon{Code: &code, Name: "new"}).Err())
+	require.NoError(t, repo.Save(ctx, &Coupon{Code: &code, Name: "renamed"}).Err())
+	c, err := repo.FindByPK(ctx, code)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", c.Name)
+}
+
+func TestRepository_SaveVersion(t *testing.T) {
+	db, err := Open("sqlite3", "file:repository_version.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE version_model(id INTEGER PRIMARY KEY, name TEXT, version INTEGER);")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	repo := NewRepository[VersionModel](db)
+	require.NoError(t, repo.Save(ctx, &VersionModel{Name: "Tom"}).Err())
+	vm, err := repo.FindByPK(ctx, 1)
+	require.NoError(t, err)
+	stale, err := repo.FindByPK(ctx, 1)
+	require.NoError(t, err)
+
+	// 主键不是零值，检查并且增加版本号
+	vm.Name = "Jerry"
+	require.NoError(t, repo.Save(ctx, vm).Err())
+	assert.Equal(t, int32(1), vm.Version)
+
+	// 版本号已经过期，不能覆盖别人的修改
+	stale.Name = "Mouse"
+	assert.Equal(t, errs.ErrStaleObject, repo.Save(ctx, stale).Err())
+	vm, err = repo.FindByPK(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &VersionModel{Id: 1, Name: "Jerry", Version: 1}, vm)
+}
+
+type Coupon struct {
+	Code *string `orm:"primaryKey"`
+	Name string
+}
+
+type OrderItem struct {
+	OrderId int64 `orm:"primaryKey"`
+	ItemId  int64 `orm:"primaryKey"`
+	Qty     int
+}
+
+type BadTagModel struct {
+	Id int64 `orm:"primary"`
+}
@@ -1,22 +1,80 @@
 package toyorm
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 type Result struct {
 	res sql.Result
 	err error
 }
 
+func (r Result) Err() error {
+	return r.err
+}
+
 func (r Result) LastInsertId() (int64, error) {
 	if r.err != nil {
-		return 0, nil
+		return 0, r.err
 	}
 	return r.res.LastInsertId()
 }
 
 func (r Result) RowsAffected() (int64, error) {
 	if r.err != nil {
-		return 0, nil
+		return 0, r.err
 	}
 	return r.res.RowsAffected()
 }
+
+// exec 让 INSERT、UPDATE、DELETE 语句和查询一样经过中间件
+func exec(ctx context.Context, sess Session, qb QueryBuilder, typ string) Result {
+	var root Handler = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		q, err := qc.Builder.Build()
+		if err != nil {
+			return &QueryResult{Err: err}
+		}
+		res, err := sess.execContext(ctx, q.SQL, q.Args...)
+		return &QueryResult{
+			Result: res,
+			Err:    err,
+		}
+	}
+	return execHandler(ctx, sess, qb, typ, root)
+}
+
+// execHandler 用中间件包装 root，root 返回的 Result 必须是 sql.Result
+func execHandler(ctx context.Context, sess Session, qb QueryBuilder, typ string, root Handler) Result {
+	ms := sess.getCore().ms
+	for i := len(ms) - 1; i >= 0; i-- {
+		root = ms[i](root)
+	}
+
+	res := root(ctx, &QueryContext{
+		Type:    typ,
+		Builder: qb,
+	})
+	var sqlRes sql.Result
+	if res.Result != nil {
+		sqlRes = res.Result.(sql.Result)
+	}
+	return Result{
+		res: sqlRes,
+		err: res.Err,
+	}
+}
+
+// rowsResult 用于没有 sql.Result 的场景，例如 INSERT ... RETURNING
+type rowsResult struct {
+	lastInsertId int64
+	rowsAffected int64
+}
+
+func (r rowsResult) LastInsertId() (int64, error) {
+	return r.lastInsertId, nil
+}
+
+func (r rowsResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
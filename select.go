@@ -16,11 +16,17 @@ type Selector[T any] struct {
 	tableName TableReference
 	where     []Predicate
 	columns   []Selectable
-	groupBy   []Column
+	groupBy   []Expression
 	orderBy   []OrderBy
 	having    []Predicate
 	offset    int
 	limit     int
+	lock      Lock
+	withs     []*cteDef
+
+	tracker *Tracker
+	// unscoped 不过滤软删除的数据
+	unscoped bool
 }
 
 // NewSelector 泛型T不支持指针
@@ -30,7 +36,7 @@ func NewSelector[T any](sess Session) *Selector[T] {
 		SQLBuilder: SQLBuilder{
 			core: sess.getCore(),
 		},
-		valCreator: valuer.NewUnsafeValue,
+		valCreator: sess.getCore().valCreator,
 	}
 }
 
@@ -47,13 +53,27 @@ func (s *Selector[T]) Where(ps ...Predicate) *Selector[T] {
 	return s
 }
 
+// With 定义一个 CTE，之后可以在 From 和 Join 里面通过 CTE(name) 引用
+func (s *Selector[T]) With(name string, q SelectQuery) *Selector[T] {
+	s.withs = append(s.withs, &cteDef{name: name, anchor: q})
+	return s
+}
+
+// WithRecursive 定义一个递归 CTE，生成 anchor UNION ALL recursive，
+// recursive 里面可以通过 CTE(name) 引用这个 CTE 本身
+func (s *Selector[T]) WithRecursive(name string, anchor SelectQuery, recursive SelectQuery) *Selector[T] {
+	s.withs = append(s.withs, &cteDef{name: name, anchor: anchor, recursive: recursive})
+	return s
+}
+
 // From select 语句的 from 指定表名
 func (s *Selector[T]) From(table TableReference) *Selector[T] {
 	s.tableName = table
 	return s
 }
 
-func (s *Selector[T]) GroupBy(cols ...Column) *Selector[T] {
+// GroupBy 可以是列，也可以是其它表达式，例如 CASE WHEN 或者 Alias
+func (s *Selector[T]) GroupBy(cols ...Expression) *Selector[T] {
 	s.groupBy = cols
 	return s
 }
@@ -63,8 +83,10 @@ func (s *Selector[T]) Having(ps ...Predicate) *Selector[T] {
 	return s
 }
 
-func (s *Selector[T]) OrderBy(os ...OrderBy) *Selector[T] {
-	s.orderBy = os
+// OrderBy 可以是 Asc、Desc 构造的排序项，也可以是任意表达式，
+// 例如 Alias("avg_age").Desc()、Raw("FIELD(`id`, 3, 1, 2)")
+func (s *Selector[T]) OrderBy(os ...Expression) *Selector[T] {
+	s.orderBy = toOrderBys(os)
 	return s
 }
 
@@ -78,6 +100,45 @@ func (s *Selector[T]) Limit(limit int) *Selector[T] {
 	return s
 }
 
+// ForUpdate 加上 FOR UPDATE 行锁，只能在事务中使用
+func (s *Selector[T]) ForUpdate() *Selector[T] {
+	s.lock.mode = lockForUpdate
+	return s
+}
+
+// ForShare 加上共享锁，只能在事务中使用
+func (s *Selector[T]) ForShare() *Selector[T] {
+	s.lock.mode = lockForShare
+	return s
+}
+
+// NoWait 在拿不到锁的时候立刻返回错误，需要和 ForUpdate 或者 ForShare 一起使用，
+// 调用的先后顺序没有关系
+func (s *Selector[T]) NoWait() *Selector[T] {
+	s.lock.wait = lockNoWait
+	return s
+}
+
+// SkipLocked 跳过已经被锁住的行，需要和 ForUpdate 或者 ForShare 一起使用，
+// 调用的先后顺序没有关系
+func (s *Selector[T]) SkipLocked() *Selector[T] {
+	s.lock.wait = lockSkipLocked
+	return s
+}
+
+// Unscoped 查询的时候包括已经软删除的数据
+func (s *Selector[T]) Unscoped() *Selector[T] {
+	s.unscoped = true
+	return s
+}
+
+// Track 把查询出来的实体记录到 tr 里面，
+// 之后使用同一个 tr 的 Updater 只会更新修改过的字段
+func (s *Selector[T]) Track(tr *Tracker) *Selector[T] {
+	s.tracker = tr
+	return s
+}
+
 // Get 数据库查询
 func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 	var root Handler = func(ctx context.Context, qc *QueryContext) *QueryResult {
@@ -91,6 +152,8 @@ func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 			return &QueryResult{Err: err}
 		}
 
+		defer func() { _ = rows.Close() }()
+
 		if !rows.Next() {
 			return &QueryResult{Err: errs.ErrNoRows}
 		}
@@ -118,66 +181,167 @@ func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 	}
 
 	if t, ok := res.Result.(*T); ok {
+		if err := s.afterFind(ctx, t); err != nil {
+			return nil, err
+		}
 		return t, nil
 	}
 
 	return nil, errors.New("ORM: 非正常格式")
 }
 
+// GetMulti 查询多行数据
+func (s *Selector[T]) GetMulti(ctx context.Context) ([]*T, error) {
+	ts, err := getMulti[T](ctx, s.sess, &s.SQLBuilder, s, s.valCreator)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.afterFind(ctx, ts...); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// afterFind 先调用 AfterFind 钩子再记录实体，钩子对实体的修改不会被当成修改过的字段
+func (s *Selector[T]) afterFind(ctx context.Context, ts ...*T) error {
+	if err := runHooks(ctx, ts, AfterFindHook.AfterFind); err != nil {
+		return err
+	}
+	return s.track(ts...)
+}
+
+func (s *Selector[T]) track(ts ...*T) error {
+	if s.tracker == nil {
+		return nil
+	}
+	for _, t := range ts {
+		if err := s.tracker.track(t, s.valCreator(t, s.model), s.model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMulti 执行 qb 构造的查询，并把结果集逐行写入 T。
+// b 是 qb 内嵌的 SQLBuilder，Build 之后从中拿到元数据
+func getMulti[T any](ctx context.Context, sess Session, b *SQLBuilder,
+	qb QueryBuilder, creator valuer.Creator) ([]*T, error) {
+	var root Handler = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		q, err := qc.Builder.Build()
+		if err != nil {
+			return &QueryResult{Err: err}
+		}
+		rows, err := sess.queryContext(ctx, q.SQL, q.Args...)
+		if err != nil {
+			return &QueryResult{Err: err}
+		}
+		defer func() { _ = rows.Close() }()
+
+		res := make([]*T, 0, 8)
+		for rows.Next() {
+			tp := new(T)
+			if err = creator(tp, b.model).SetColumns(rows); err != nil {
+				return &QueryResult{Err: err}
+			}
+			res = append(res, tp)
+		}
+		return &QueryResult{
+			Result: res,
+			Err:    rows.Err(),
+		}
+	}
+
+	for i := len(b.ms) - 1; i >= 0; i-- {
+		root = b.ms[i](root)
+	}
+
+	res := root(ctx, &QueryContext{
+		Type:    SQLSelect,
+		Builder: qb,
+	})
+
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	if ts, ok := res.Result.([]*T); ok {
+		return ts, nil
+	}
+
+	return nil, errors.New("ORM: 非正常格式")
+}
+
 func (s *Selector[T]) Build() (*Query, error) {
+	if err := s.build(); err != nil {
+		return nil, err
+	}
+	return &Query{
+		SQL:  s.string(),
+		Args: s.args,
+	}, nil
+}
+
+// build 构造不带结尾分号的 SELECT 语句，
+// 这样 UNION 之类的组合查询可以直接复用
+func (s *Selector[T]) build() error {
 	var (
 		err error
 		t   T
 	)
 
+	s.reset()
 	s.model, err = s.r.Get(&t)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	s.ctes = append(append([]*cteDef{}, s.outerCTEs...), s.withs...)
+	s.selectList = s.columns
+	if err = s.buildWith(s.withs); err != nil {
+		return err
 	}
 
 	s.builder.WriteString(SQLSelect)
 	if err = s.buildColumns(); err != nil {
-		return nil, err
+		return err
 	}
 
-	s.buildFrom()
+	from, where := s.tableName, s.where
+	if !s.unscoped {
+		var scope []Predicate
+		if from, scope, err = s.softDeleteScope(from); err != nil {
+			return err
+		}
+		where = append(where[:len(where):len(where)], scope...)
+	}
+
+	if err = s.buildFrom(from); err != nil {
+		return err
+	}
 
-	err = s.buildWhere()
+	err = s.buildWhere(where)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = s.buildGroupBy()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = s.buildHaving()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = s.buildOrderBy()
+	err = s.buildOrderBy(s.orderBy)
 	if err != nil {
-		return nil, err
-	}
-
-	if s.limit > 0 {
-		s.Margin(SQLLimit)
-		s.builder.WriteString("?")
-		s.AddArgs(s.limit)
+		return err
 	}
 
-	if s.offset > 0 {
-		s.Margin(SQLOffset)
-		s.builder.WriteString("?")
-		s.AddArgs(s.offset)
-	}
+	s.buildLimitOffset(s.limit, s.offset)
 
-	return &Query{
-		SQL:  s.string(),
-		Args: s.args,
-	}, nil
+	return s.buildLock()
 }
 
 func (s *Selector[T]) buildColumns() error {
@@ -192,7 +356,7 @@ func (s *Selector[T]) buildColumns() error {
 		}
 		switch col := c.(type) {
 		case Column:
-			if err := s.buildColumn(col.name); err != nil {
+			if err := s.buildTableColumn(col); err != nil {
 				return err
 			}
 			s.As(col.alias)
@@ -200,10 +364,21 @@ func (s *Selector[T]) buildColumns() error {
 			if err := s.buildAggregate(col, true); err != nil {
 				return err
 			}
+		case WindowFunc:
+			if err := s.buildWindowFunc(col, true); err != nil {
+				return err
+			}
+		case CaseExpr:
+			if err := s.buildCase(col, true); err != nil {
+				return err
+			}
+		case JSONPathExpr:
+			if err := s.buildJSONPath(col, true); err != nil {
+				return err
+			}
 		case RawExpr:
-			s.builder.WriteString(col.raw)
-			if len(col.args) != 0 {
-				s.args = append(s.args, col.args...)
+			if err := s.buildRawExpr(col); err != nil {
+				return err
 			}
 		}
 	}
@@ -211,23 +386,17 @@ func (s *Selector[T]) buildColumns() error {
 	return nil
 }
 
-func (s *Selector[T]) buildWhere() error {
-	if len(s.where) != 0 {
+func (s *Selector[T]) buildWhere(where []Predicate) error {
+	if len(where) != 0 {
 		s.Margin(SQLWhere)
-		return s.buildPredicates(s.where)
+		return s.buildPredicates(where)
 	}
 	return nil
 }
 
-func (s *Selector[T]) buildFrom() {
+func (s *Selector[T]) buildFrom(from TableReference) error {
 	s.Margin(SQLFrom)
-	switch s.tableName.(type) {
-	case nil:
-		s.Quota(s.model.TableName)
-	case Table:
-
-	case Join:
-	}
+	return s.buildTableReference(from)
 }
 
 func (s *Selector[T]) buildGroupBy() error {
@@ -237,7 +406,7 @@ func (s *Selector[T]) buildGroupBy() error {
 			if i > 0 {
 				s.Comma()
 			}
-			if err := s.buildColumn(c.name); err != nil {
+			if err := s.buildExpression(c); err != nil {
 				return err
 			}
 		}
@@ -253,21 +422,35 @@ func (s *Selector[T]) buildHaving() error {
 	return nil
 }
 
-func (s *Selector[T]) buildOrderBy() error {
-	if len(s.orderBy) != 0 {
-		s.Margin(SQLOrderBy)
-		for i, o := range s.orderBy {
-			if i > 0 {
-				s.Comma()
-			}
-			if err := s.buildColumn(o.col); err != nil {
-				return err
-			}
-			s.builder.WriteString(" ")
-			s.builder.WriteString(o.order)
+func (s *Selector[T]) buildLock() error {
+	if s.lock.mode == "" {
+		if s.lock.wait != lockWaitDefault {
+			return errs.ErrLockWaitWithoutMode
 		}
+		return nil
 	}
-	return nil
+	// 行锁脱离了事务没有任何意义，语句结束锁就释放了
+	if _, ok := s.sess.(*Tx); !ok {
+		return errs.ErrLockOutsideTx
+	}
+	return s.dialect.BuildLock(&s.SQLBuilder, &s.lock)
+}
+
+func (s *Selector[T]) sqlBuilder() *SQLBuilder {
+	return &s.SQLBuilder
+}
+
+func (s *Selector[T]) selectedColumns() []Selectable {
+	return s.columns
+}
+
+// SelectQuery 可以作为子查询使用的 SELECT 语句，
+// 例如 CTE 的定义以及 INSERT ... SELECT 的数据来源，目前只有 Selector 实现了
+type SelectQuery interface {
+	// build 构造不带分号的语句
+	build() error
+	sqlBuilder() *SQLBuilder
+	selectedColumns() []Selectable
 }
 
 type Selectable interface {
@@ -38,6 +38,66 @@ func TestSelector_OrderBy(t *testing.T) {
 			q:       NewSelector[TestModel](db).OrderBy(Asc("Invalid")),
 			wantErr: errs.NewErrUnknownField("Invalid"),
 		},
+		{
+			// 没有指定顺序
+			name: "expression",
+			q:    NewSelector[TestModel](db).OrderBy(Col("Age"), Avg("Age").Desc()),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` ORDER BY `age`, AVG(`age`) DESC;",
+			},
+		},
+		{
+			name: "raw expression",
+			q:    NewSelector[TestModel](db).OrderBy(Raw("FIELD(`id`, ?, ?)", 3, 1).Asc()),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` ORDER BY FIELD(`id`, ?, ?) ASC;",
+				Args: []any{3, 1},
+			},
+		},
+		{
+			name: "alias",
+			q: NewSelector[TestModel](db).Select(Col("Age"), Avg("Id").AS("avg_id")).
+				GroupBy(Col("Age")).OrderBy(Alias("avg_id").Desc()),
+			wantQuery: &Query{
+				SQL: "SELECT `age`, AVG(`id`) AS `avg_id` FROM `test_model` GROUP BY `age` ORDER BY `avg_id` DESC;",
+			},
+		},
+		{
+			name:    "unknown alias",
+			q:       NewSelector[TestModel](db).Select(Col("Age")).OrderBy(Alias("avg_id").Desc()),
+			wantErr: errs.NewErrUnknownAlias("avg_id"),
+		},
+		{
+			// MySQL 默认 NULL 最小，和默认顺序一致的时候不需要处理
+			name: "nulls default",
+			q:    NewSelector[TestModel](db).OrderBy(Asc("LastName").NullsFirst(), Desc("Age").NullsLast()),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` ORDER BY `last_name` ASC, `age` DESC;",
+			},
+		},
+		{
+			name: "nulls emulated",
+			q:    NewSelector[TestModel](db).OrderBy(Asc("LastName").NullsLast(), Desc("Age").NullsFirst()),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` ORDER BY `last_name` IS NULL ASC, `last_name` ASC, `age` IS NULL DESC, `age` DESC;",
+			},
+		},
+		{
+			name: "nulls emulated with args",
+			q:    NewSelector[TestModel](db).OrderBy(Raw("NULLIF(`age`, ?)", 0).Asc().NullsLast()),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` ORDER BY NULLIF(`age`, ?) IS NULL ASC, NULLIF(`age`, ?) ASC;",
+				Args: []any{0, 0},
+			},
+		},
+		{
+			name: "nulls sqlite",
+			q: NewSelector[TestModel](memoryDB(t, DBWithDialect(SQLite))).
+				OrderBy(Asc("LastName").NullsLast(), Desc("Age").NullsFirst()),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` ORDER BY `last_name` ASC NULLS LAST, `age` DESC NULLS FIRST;",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -195,6 +255,21 @@ func TestSelector_GroupBy(t *testing.T) {
 			q:       NewSelector[TestModel](db).GroupBy(Col("Invalid")),
 			wantErr: errs.NewErrUnknownField("Invalid"),
 		},
+		{
+			name: "alias",
+			q: NewSelector[TestModel](db).Select(Col("Age").AS("my_age"), Count("Id")).
+				GroupBy(Alias("my_age")),
+			wantQuery: &Query{
+				SQL: "SELECT `age` AS `my_age`, COUNT(`id`) FROM `test_model` GROUP BY `my_age`;",
+			},
+		},
+		{
+			name: "raw expression",
+			q:    NewSelector[TestModel](db).GroupBy(Raw("YEAR(`age`)")),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` GROUP BY YEAR(`age`);",
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -302,7 +377,7 @@ func TestSelector_Build(t *testing.T) {
 		{
 			// 调用 FROM
 			name: "with from",
-			q:    NewSelector[TestModel](db).From("`test_model_t`"),
+			q:    NewSelector[TestModel](db).From(Raw("`test_model_t`")),
 			wantQuery: &Query{
 				SQL: "SELECT * FROM `test_model_t`;",
 			},
@@ -310,7 +385,7 @@ func TestSelector_Build(t *testing.T) {
 		{
 			// 调用 FROM，但是传入空字符串
 			name: "empty from",
-			q:    NewSelector[TestModel](db).From(""),
+			q:    NewSelector[TestModel](db).From(Raw("")),
 			wantQuery: &Query{
 				SQL: "SELECT * FROM `test_model`;",
 			},
@@ -318,7 +393,7 @@ func TestSelector_Build(t *testing.T) {
 		{
 			// 调用 FROM，同时出入看了 DB
 			name: "with db",
-			q:    NewSelector[TestModel](db).From("`test_db`.`test_model`"),
+			q:    NewSelector[TestModel](db).From(Raw("`test_db`.`test_model`")),
 			wantQuery: &Query{
 				SQL: "SELECT * FROM `test_db`.`test_model`;",
 			},
@@ -326,7 +401,7 @@ func TestSelector_Build(t *testing.T) {
 		{
 			// 单一简单条件
 			name: "single and simple predicate",
-			q: NewSelector[TestModel](db).From("`test_model_t`").
+			q: NewSelector[TestModel](db).From(Raw("`test_model_t`")).
 				Where(Col("Id").EQ(1)),
 			wantQuery: &Query{
 				SQL:  "SELECT * FROM `test_model_t` WHERE `id` = ?;",
@@ -379,6 +454,11 @@ func TestSelector_Build(t *testing.T) {
 			q:       NewSelector[TestModel](db).Where(NOT(Col("Invalid").GT(18))),
 			wantErr: errs.NewErrUnknownField("Invalid"),
 		},
+		{
+			name:    "invalid tag",
+			q:       NewSelector[BadTagModel](db),
+			wantErr: errs.NewErrInvalidTagContent("primary"),
+		},
 		//{
 		//	// 使用 RawExpr
 		//	name: "raw expression",
@@ -403,6 +483,221 @@ func TestSelector_Build(t *testing.T) {
 	}
 }
 
+func TestSelector_Join(t *testing.T) {
+	db := memoryDB(t)
+	t1 := TableOf(&TestModel{}).As("t1")
+	t2 := TableOf(&Category{})
+	sm := TableOf(&SoftModel{}).As("m")
+	si := TableOf(&SoftItem{}).As("i")
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "table",
+			q:    NewSelector[TestModel](db).From(t1).Where(t1.C("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` AS `t1` WHERE `t1`.`id` = ?;",
+				Args: []any{1},
+			},
+		},
+		{
+			name: "join on",
+			q: NewSelector[TestModel](db).Select(t1.C("Id"), t2.C("Name")).
+				From(t1.Join(t2).On(t1.C("Id").EQ(t2.C("ParentId")))),
+			wantQuery: &Query{
+				SQL: "SELECT `t1`.`id`, `category`.`name` FROM (`test_model` AS `t1` JOIN `category` ON `t1`.`id` = `category`.`parent_id`);",
+			},
+		},
+		{
+			name: "left join using",
+			q:    NewSelector[TestModel](db).From(t1.LeftJoin(TableOf(&TestModel{}).As("t3")).Using("Id")),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM (`test_model` AS `t1` LEFT JOIN `test_model` AS `t3` USING (`id`));",
+			},
+		},
+		{
+			// Name 不是 TestModel 的字段，按照 JOIN 两边的模型解析
+			name: "join using other models",
+			q:    NewSelector[TestModel](db).From(t2.Join(TableOf(&SoftModel{})).Using("Name")),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM (`category` JOIN `soft_model` USING (`name`)) " +
+					"WHERE `soft_model`.`deleted_at` IS NULL;",
+			},
+		},
+		{
+			name:    "join using missing on one side",
+			q:       NewSelector[TestModel](db).From(t1.Join(t2).Using("FirstName")),
+			wantErr: errs.NewErrUnknownField("FirstName"),
+		},
+		{
+			name:    "invalid column",
+			q:       NewSelector[TestModel](db).From(t1.Join(t2).On(t1.C("Id").EQ(t2.C("Invalid")))),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "soft delete",
+			q:    NewSelector[SoftModel](db).Where(Col("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `soft_model` WHERE (`id` = ?) AND (`deleted_at` IS NULL);",
+				Args: []any{1},
+			},
+		},
+		{
+			name: "soft delete unscoped",
+			q:    NewSelector[SoftModel](db).Where(Col("Id").EQ(1)).Unscoped(),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `soft_model` WHERE `id` = ?;",
+				Args: []any{1},
+			},
+		},
+		{
+			// 被 JOIN 的表的条件放在 ON 里面，LEFT JOIN 不会变成 INNER JOIN
+			name: "soft delete left join",
+			q: NewSelector[SoftModel](db).From(sm.LeftJoin(si).On(sm.C("Id").EQ(si.C("ModelId")))).
+				Where(sm.C("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM (`soft_model` AS `m` LEFT JOIN `soft_item` AS `i` " +
+					"ON (`m`.`id` = `i`.`model_id`) AND (`i`.`deleted_at` IS NULL)) " +
+					"WHERE (`m`.`id` = ?) AND (`m`.`deleted_at` IS NULL);",
+				Args: []any{1},
+			},
+		},
+		{
+			name: "soft delete join using",
+			q:    NewSelector[SoftModel](db).From(t1.Join(sm).Using("Id")),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM (`test_model` AS `t1` JOIN `soft_model` AS `m` USING (`id`)) " +
+					"WHERE `m`.`deleted_at` IS NULL;",
+			},
+		},
+		{
+			name: "soft delete nested join",
+			q: NewSelector[SoftModel](db).From(t1.Join(sm).On(t1.C("Id").EQ(sm.C("Id"))).
+				Join(si).On(sm.C("Id").EQ(si.C("ModelId")))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM ((`test_model` AS `t1` JOIN `soft_model` AS `m` " +
+					"ON (`t1`.`id` = `m`.`id`) AND (`m`.`deleted_at` IS NULL)) JOIN `soft_item` AS `i` " +
+					"ON (`m`.`id` = `i`.`model_id`) AND (`i`.`deleted_at` IS NULL));",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSelector_Lock(t *testing.T) {
+	db := memoryDB(t)
+	tx, err := db.Begin(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.RollBack() }()
+
+	sqliteDB := memoryDB(t, DBWithDialect(SQLite))
+	sqliteTx, err := sqliteDB.Begin(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sqliteTx.RollBack() }()
+
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "for update",
+			q:    NewSelector[TestModel](tx).Where(Col("Id").EQ(1)).ForUpdate(),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` = ? FOR UPDATE;",
+				Args: []any{1},
+			},
+		},
+		{
+			name: "for update skip locked",
+			q:    NewSelector[TestModel](tx).Limit(10).ForUpdate().SkipLocked(),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` LIMIT ? FOR UPDATE SKIP LOCKED;",
+				Args: []any{10},
+			},
+		},
+		{
+			name: "for update nowait",
+			q:    NewSelector[TestModel](tx).ForUpdate().NoWait(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` FOR UPDATE NOWAIT;",
+			},
+		},
+		{
+			// MySQL 5.7 兼容写法
+			name: "for share",
+			q:    NewSelector[TestModel](tx).ForShare(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` LOCK IN SHARE MODE;",
+			},
+		},
+		{
+			name: "for share nowait",
+			q:    NewSelector[TestModel](tx).ForShare().NoWait(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` FOR SHARE NOWAIT;",
+			},
+		},
+		{
+			// 先调用 SkipLocked 再调用 ForUpdate
+			name: "skip locked before for update",
+			q:    NewSelector[TestModel](tx).SkipLocked().ForUpdate(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` FOR UPDATE SKIP LOCKED;",
+			},
+		},
+		{
+			name:    "skip locked only",
+			q:       NewSelector[TestModel](tx).SkipLocked(),
+			wantErr: errs.ErrLockWaitWithoutMode,
+		},
+		{
+			name:    "nowait only",
+			q:       NewSelector[TestModel](tx).NoWait(),
+			wantErr: errs.ErrLockWaitWithoutMode,
+		},
+		{
+			name:    "outside tx",
+			q:       NewSelector[TestModel](db).ForUpdate(),
+			wantErr: errs.ErrLockOutsideTx,
+		},
+		{
+			name:    "sqlite",
+			q:       NewSelector[TestModel](sqliteTx).ForUpdate(),
+			wantErr: errs.NewErrDialectUnsupported("sqlite", "FOR UPDATE"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
 func TestSelector_Get(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
@@ -483,6 +778,80 @@ func TestSelector_Get(t *testing.T) {
 	}
 }
 
+func TestSelector_GetMulti(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		mockErr  error
+		mockRows *sqlmock.Rows
+		wantErr  error
+		wantVal  []*TestModel
+	}{
+		{
+			name:    "query error",
+			mockErr: errors.New("invalid query"),
+			wantErr: errors.New("invalid query"),
+		},
+		{
+			name:     "no row",
+			mockRows: sqlmock.NewRows([]string{"id"}),
+			wantVal:  []*TestModel{},
+		},
+		{
+			name: "multiple rows",
+			mockRows: func() *sqlmock.Rows {
+				res := sqlmock.NewRows([]string{"id", "first_name", "age", "last_name"})
+				res.AddRow([]byte("1"), []byte("Da"), []byte("18"), []byte("Ming"))
+				res.AddRow([]byte("2"), []byte("Xiao"), []byte("16"), []byte("Hong"))
+				return res
+			}(),
+			wantVal: []*TestModel{
+				{
+					Id:        1,
+					FirstName: "Da",
+					Age:       18,
+					LastName:  &sql.NullString{String: "Ming", Valid: true},
+				},
+				{
+					Id:        2,
+					FirstName: "Xiao",
+					Age:       16,
+					LastName:  &sql.NullString{String: "Hong", Valid: true},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		exp := mock.ExpectQuery("SELECT .*")
+		if tc.mockErr != nil {
+			exp.WillReturnError(tc.mockErr)
+		} else {
+			exp.WillReturnRows(tc.mockRows)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := NewSelector[TestModel](db).GetMulti(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, res)
+		})
+	}
+}
+
 func memoryDB(t *testing.T, opts ...DBOption) *DB {
 	orm, err := Open("sqlite3", "file:test.db?cache=shared&mode=memory", opts...)
 	if err != nil {
@@ -0,0 +1,164 @@
+package toyorm
+
+import (
+	"context"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/aristletl/toyorm/internal/valuer"
+)
+
+// SetQuery 代表 UNION、UNION ALL、INTERSECT 和 EXCEPT 组合起来的查询，
+// 所有子查询都必须返回相同数量的列，结果集会被写入 T
+type SetQuery[T any] struct {
+	SQLBuilder
+	sess       Session
+	valCreator valuer.Creator
+
+	first   *Selector[T]
+	parts   []setPart[T]
+	orderBy []OrderBy
+	offset  int
+	limit   int
+}
+
+type setPart[T any] struct {
+	op string
+	s  *Selector[T]
+}
+
+// Union 和 other 组合成 UNION 查询，结果会去重
+func (s *Selector[T]) Union(other *Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).Union(other)
+}
+
+// UnionAll 和 other 组合成 UNION ALL 查询
+func (s *Selector[T]) UnionAll(other *Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).UnionAll(other)
+}
+
+// Intersect 和 other 组合成 INTERSECT 查询，MySQL 8.0.31 之后才支持
+func (s *Selector[T]) Intersect(other *Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).Intersect(other)
+}
+
+// Except 和 other 组合成 EXCEPT 查询，MySQL 8.0.31 之后才支持
+func (s *Selector[T]) Except(other *Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).Except(other)
+}
+
+func newSetQuery[T any](first *Selector[T]) *SetQuery[T] {
+	return &SetQuery[T]{
+		sess: first.sess,
+		SQLBuilder: SQLBuilder{
+			core: first.sess.getCore(),
+		},
+		valCreator: first.valCreator,
+		first:      first,
+	}
+}
+
+func (q *SetQuery[T]) Union(other *Selector[T]) *SetQuery[T] {
+	q.parts = append(q.parts, setPart[T]{op: SQLUnion, s: other})
+	return q
+}
+
+func (q *SetQuery[T]) UnionAll(other *Selector[T]) *SetQuery[T] {
+	q.parts = append(q.parts, setPart[T]{op: SQLUnionAll, s: other})
+	return q
+}
+
+func (q *SetQuery[T]) Intersect(other *Selector[T]) *SetQuery[T] {
+	q.parts = append(q.parts, setPart[T]{op: SQLIntersect, s: other})
+	return q
+}
+
+func (q *SetQuery[T]) Except(other *Selector[T]) *SetQuery[T] {
+	q.parts = append(q.parts, setPart[T]{op: SQLExcept, s: other})
+	return q
+}
+
+// OrderBy 作用于整个组合查询的结果
+func (q *SetQuery[T]) OrderBy(os ...Expression) *SetQuery[T] {
+	q.orderBy = toOrderBys(os)
+	return q
+}
+
+func (q *SetQuery[T]) Offset(offset int) *SetQuery[T] {
+	q.offset = offset
+	return q
+}
+
+func (q *SetQuery[T]) Limit(limit int) *SetQuery[T] {
+	q.limit = limit
+	return q
+}
+
+// GetMulti 执行组合查询
+func (q *SetQuery[T]) GetMulti(ctx context.Context) ([]*T, error) {
+	return getMulti[T](ctx, q.sess, &q.SQLBuilder, q, q.valCreator)
+}
+
+func (q *SetQuery[T]) Build() (*Query, error) {
+	var (
+		err error
+		t   T
+	)
+
+	q.reset()
+	q.model, err = q.r.Get(&t)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组合查询的列名由第一个子查询决定
+	q.selectList = q.first.columns
+	if err = q.buildSelector(q.first); err != nil {
+		return nil, err
+	}
+	colCnt := q.columnCount(q.first)
+	for _, p := range q.parts {
+		if q.columnCount(p.s) != colCnt {
+			return nil, errs.ErrSetColumnsMismatch
+		}
+		q.Margin(p.op)
+		if err = q.buildSelector(p.s); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = q.buildOrderBy(q.orderBy); err != nil {
+		return nil, err
+	}
+	q.buildLimitOffset(q.limit, q.offset)
+
+	return &Query{
+		SQL:  q.string(),
+		Args: q.args,
+	}, nil
+}
+
+// buildSelector 把子查询拼接进来，参数按照子查询出现的顺序追加。
+// 子查询带有 ORDER BY 或者 LIMIT 的时候需要通过方言包起来，
+// 否则它们会被当成整个组合查询的 ORDER BY 和 LIMIT
+func (q *SetQuery[T]) buildSelector(s *Selector[T]) error {
+	if err := s.build(); err != nil {
+		return err
+	}
+	if len(s.orderBy) > 0 || s.limit > 0 || s.offset > 0 {
+		if err := q.dialect.BuildSetOperand(&q.SQLBuilder, s.builder.String()); err != nil {
+			return err
+		}
+	} else {
+		q.builder.WriteString(s.builder.String())
+	}
+	q.AddArgs(s.args...)
+	return nil
+}
+
+// columnCount SELECT * 的时候使用模型的列数
+func (q *SetQuery[T]) columnCount(s *Selector[T]) int {
+	if len(s.columns) == 0 {
+		return len(q.model.Columns)
+	}
+	return len(s.columns)
+}
@@ -0,0 +1,158 @@
+package toyorm
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetQuery_Build(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "union",
+			q: NewSelector[TestModel](db).Where(Col("Age").GT(18)).
+				Union(NewSelector[TestModel](db).From(Raw("`test_model_2021`")).Where(Col("Age").LT(10))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` > ? UNION SELECT * FROM `test_model_2021` WHERE `age` < ?;",
+				Args: []any{18, 10},
+			},
+		},
+		{
+			name: "union all",
+			q: NewSelector[TestModel](db).Select(Col("Id")).
+				UnionAll(NewSelector[TestModel](db).Select(Col("Id"))),
+			wantQuery: &Query{
+				SQL: "SELECT `id` FROM `test_model` UNION ALL SELECT `id` FROM `test_model`;",
+			},
+		},
+		{
+			name: "intersect except",
+			q: NewSelector[TestModel](db).Where(Col("Id").EQ(1)).
+				Intersect(NewSelector[TestModel](db).Where(Col("Id").EQ(2))).
+				Except(NewSelector[TestModel](db).Where(Col("Id").EQ(3))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `id` = ? INTERSECT SELECT * FROM `test_model` WHERE `id` = ? " +
+					"EXCEPT SELECT * FROM `test_model` WHERE `id` = ?;",
+				Args: []any{1, 2, 3},
+			},
+		},
+		{
+			// ORDER BY 和 LIMIT 作用于整个结果，参数在所有子查询之后
+			name: "outer order by limit",
+			q: NewSelector[TestModel](db).Where(Col("Age").GT(18)).
+				Union(NewSelector[TestModel](db).Where(Col("Age").LT(10))).
+				OrderBy(Desc("Age")).Limit(10).Offset(5),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` > ? UNION SELECT * FROM `test_model` WHERE `age` < ? ORDER BY `age` DESC LIMIT ? OFFSET ?;",
+				Args: []any{18, 10, 10, 5},
+			},
+		},
+		{
+			// 子查询自己的 LIMIT 需要括号
+			name: "inner limit",
+			q: NewSelector[TestModel](db).Limit(3).
+				Union(NewSelector[TestModel](db).Where(Col("Age").LT(10))),
+			wantQuery: &Query{
+				SQL:  "(SELECT * FROM `test_model` LIMIT ?) UNION SELECT * FROM `test_model` WHERE `age` < ?;",
+				Args: []any{3, 10},
+			},
+		},
+		{
+			name: "columns mismatch",
+			q: NewSelector[TestModel](db).Select(Col("Id")).
+				Union(NewSelector[TestModel](db)),
+			wantErr: errs.ErrSetColumnsMismatch,
+		},
+		{
+			name: "invalid column",
+			q: NewSelector[TestModel](db).
+				Union(NewSelector[TestModel](db).Where(Col("Invalid").EQ(1))),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSetQuery_GetMulti(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "first_name", "age", "last_name"})
+	rows.AddRow([]byte("1"), []byte("Da"), []byte("18"), []byte("Ming"))
+	rows.AddRow([]byte("2"), []byte("Xiao"), []byte("8"), nil)
+	mock.ExpectQuery("SELECT .* UNION ALL SELECT .*").WithArgs(18, 10).WillReturnRows(rows)
+
+	res, err := NewSelector[TestModel](db).Where(Col("Age").GT(18)).
+		UnionAll(NewSelector[TestModel](db).Where(Col("Age").LT(10))).
+		GetMulti(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []*TestModel{
+		{
+			Id:        1,
+			FirstName: "Da",
+			Age:       18,
+			LastName:  &sql.NullString{String: "Ming", Valid: true},
+		},
+		{
+			Id:        2,
+			FirstName: "Xiao",
+			Age:       8,
+		},
+	}, res)
+}
+
+func TestSetQuery_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:set_query.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE test_model(id INTEGER PRIMARY KEY, first_name TEXT, age INTEGER, last_name TEXT);" +
+		"INSERT INTO test_model(id, first_name, age) VALUES(1, 'a', 30), (2, 'b', 20), (3, 'c', 10);")
+	require.NoError(t, err)
+
+	// sqlite 不支持 (SELECT ...) UNION ...，子查询只能放到 FROM 里面
+	q := NewSelector[TestModel](db).OrderBy(Desc("Age")).Limit(1).
+		Union(NewSelector[TestModel](db).Where(Col("Age").LT(15)))
+	query, err := q.Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL: "SELECT * FROM (SELECT * FROM `test_model` ORDER BY `age` DESC LIMIT ?) " +
+			"UNION SELECT * FROM `test_model` WHERE `age` < ?;",
+		Args: []any{1, 15},
+	}, query)
+
+	res, err := q.OrderBy(Asc("Id")).GetMulti(context.Background())
+	require.NoError(t, err)
+	ids := make([]int64, 0, len(res))
+	for _, r := range res {
+		ids = append(ids, r.Id)
+	}
+	assert.Equal(t, []int64{1, 3}, ids)
+}
@@ -12,6 +12,13 @@ type SQLBuilder struct {
 	builder strings.Builder
 	model   *model.Model
 	args    []any
+
+	// ctes 当前语句可以引用的 CTE，包括外层语句定义的
+	ctes []*cteDef
+	// outerCTEs 外层语句定义的 CTE
+	outerCTEs []*cteDef
+	// selectList SELECT 列表，用于校验 Alias 的引用
+	selectList []Selectable
 }
 
 func (s *SQLBuilder) Comma() {
@@ -66,6 +73,238 @@ func (s *SQLBuilder) buildColumn(col string) error {
 	return nil
 }
 
+// fieldOf 找到列对应的字段元数据，带表的列使用该表的模型
+func (s *SQLBuilder) fieldOf(c Column) (*model.Field, error) {
+	m := s.model
+	if tbl, ok := c.table.(Table); ok {
+		var err error
+		if m, err = s.tableModel(tbl); err != nil {
+			return nil, err
+		}
+	}
+	fd, ok := m.FieldMap[c.name]
+	if !ok {
+		return nil, errs.NewErrUnknownField(c.name)
+	}
+	return fd, nil
+}
+
+func (s *SQLBuilder) tableModel(tbl Table) (*model.Model, error) {
+	if tbl.entity == nil {
+		return s.model, nil
+	}
+	return s.r.Get(tbl.entity)
+}
+
+// buildTableColumn 带表的列会输出 `表名或别名`.`列名`
+func (s *SQLBuilder) buildTableColumn(c Column) error {
+	switch tbl := c.table.(type) {
+	case nil:
+		return s.buildColumn(c.name)
+	case Table:
+		m, err := s.tableModel(tbl)
+		if err != nil {
+			return err
+		}
+		fd, ok := m.FieldMap[c.name]
+		if !ok {
+			return errs.NewErrUnknownField(c.name)
+		}
+		if tbl.alias != "" {
+			s.Quota(tbl.alias)
+		} else {
+			s.Quota(m.TableName)
+		}
+		s.builder.WriteByte('.')
+		s.Quota(fd.ColName)
+	case CommonTable:
+		col, err := s.cteColumn(tbl, c.name)
+		if err != nil {
+			return err
+		}
+		if tbl.alias != "" {
+			s.Quota(tbl.alias)
+		} else {
+			s.Quota(tbl.name)
+		}
+		s.builder.WriteByte('.')
+		s.Quota(col)
+	default:
+		return errs.NewErrUnsupportedTable(tbl)
+	}
+	return nil
+}
+
+// buildTableReference 构造 FROM 后面的部分
+func (s *SQLBuilder) buildTableReference(table TableReference) error {
+	switch tbl := table.(type) {
+	case nil:
+		s.Quota(s.model.TableName)
+	case RawExpr:
+		// 空的表达式等价于没有调用 From
+		if tbl.raw == "" {
+			s.Quota(s.model.TableName)
+			return nil
+		}
+		return s.buildRawExpr(tbl)
+	case Table:
+		m, err := s.tableModel(tbl)
+		if err != nil {
+			return err
+		}
+		s.Quota(m.TableName)
+		s.As(tbl.alias)
+	case CommonTable:
+		if s.findCTE(tbl.name) == nil {
+			return errs.NewErrUnknownCTE(tbl.name)
+		}
+		s.Quota(tbl.name)
+		s.As(tbl.alias)
+	case Join:
+		return s.buildJoin(tbl)
+	default:
+		return errs.NewErrUnsupportedTable(tbl)
+	}
+	return nil
+}
+
+func (s *SQLBuilder) buildJoin(j Join) error {
+	s.builder.WriteString("(")
+	if err := s.buildTableReference(j.left); err != nil {
+		return err
+	}
+	s.Margin(j.typ)
+	if err := s.buildTableReference(j.right); err != nil {
+		return err
+	}
+	if len(j.on) > 0 {
+		s.Margin("ON")
+		if err := s.buildPredicates(j.on); err != nil {
+			return err
+		}
+	}
+	if len(j.using) > 0 {
+		s.Margin("USING")
+		s.builder.WriteString("(")
+		for i, c := range j.using {
+			if i > 0 {
+				s.Comma()
+			}
+			col, err := s.usingColumn(j, c)
+			if err != nil {
+				return err
+			}
+			s.Quota(col)
+		}
+		s.builder.WriteString(")")
+	}
+	s.builder.WriteString(")")
+	return nil
+}
+
+// usingColumn 找到 USING 字段对应的列名。字段要在 JOIN 的两边都存在，
+// 和 Selector 本身的模型没有关系；CTE、子查询这种没有模型的一边不检查，
+// 两边都没有模型的时候直接使用字段名
+func (s *SQLBuilder) usingColumn(j Join, name string) (string, error) {
+	col := name
+	for _, side := range []TableReference{j.left, j.right} {
+		ms, err := s.joinModels(side)
+		if err != nil {
+			return "", err
+		}
+		if len(ms) == 0 {
+			continue
+		}
+		fd, ok := findField(ms, name)
+		if !ok {
+			return "", errs.NewErrUnknownField(name)
+		}
+		col = fd.ColName
+	}
+	return col, nil
+}
+
+// joinModels JOIN 的一边涉及的所有模型，嵌套的 JOIN 会展开
+func (s *SQLBuilder) joinModels(table TableReference) ([]*model.Model, error) {
+	switch tbl := table.(type) {
+	case Table:
+		m, err := s.tableModel(tbl)
+		if err != nil {
+			return nil, err
+		}
+		return []*model.Model{m}, nil
+	case Join:
+		left, err := s.joinModels(tbl.left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := s.joinModels(tbl.right)
+		if err != nil {
+			return nil, err
+		}
+		return append(left, right...), nil
+	default:
+		return nil, nil
+	}
+}
+
+func findField(ms []*model.Model, name string) (*model.Field, bool) {
+	for _, m := range ms {
+		if fd, ok := m.FieldMap[name]; ok {
+			return fd, true
+		}
+	}
+	return nil, false
+}
+
+// softDeleteScope 给软删除的表加上 deleted_at IS NULL 的条件。
+// FROM 的表加到返回的 WHERE 条件里面；JOIN 进来的表加到 ON 里面，
+// 这样 LEFT JOIN 的语义不会改变。USING 后面没办法再加条件，只能加到 WHERE 里面
+func (s *SQLBuilder) softDeleteScope(table TableReference) (TableReference, []Predicate, error) {
+	switch tbl := table.(type) {
+	case nil:
+		return table, s.softDeletePredicates(nil, s.model), nil
+	case RawExpr:
+		if tbl.raw != "" {
+			return table, nil, nil
+		}
+		return table, s.softDeletePredicates(nil, s.model), nil
+	case Table:
+		m, err := s.tableModel(tbl)
+		if err != nil {
+			return nil, nil, err
+		}
+		return table, s.softDeletePredicates(tbl, m), nil
+	case Join:
+		left, where, err := s.softDeleteScope(tbl.left)
+		if err != nil {
+			return nil, nil, err
+		}
+		right, on, err := s.softDeleteScope(tbl.right)
+		if err != nil {
+			return nil, nil, err
+		}
+		tbl.left, tbl.right = left, right
+		if len(on) != 0 {
+			if len(tbl.using) == 0 {
+				tbl.on = append(tbl.on[:len(tbl.on):len(tbl.on)], on...)
+			} else {
+				where = append(where, on...)
+			}
+		}
+		return tbl, where, nil
+	default:
+		return table, nil, nil
+	}
+}
+
+func (s *SQLBuilder) softDeletePredicates(table TableReference, m *model.Model) []Predicate {
+	if m.SoftDelete == nil {
+		return nil
+	}
+	return []Predicate{Column{table: table, name: m.SoftDelete.Name}.IsNull()}
+}
+
 func (s *SQLBuilder) buildPredicates(pres []Predicate) error {
 	pred := pres[0]
 	for i := 1; i < len(pres); i++ {
@@ -75,10 +314,20 @@ func (s *SQLBuilder) buildPredicates(pres []Predicate) error {
 }
 
 func (s *SQLBuilder) buildPredicate(e Predicate) error {
+	// EQ(Null[T]{}) 相当于 IS NULL
+	if e.op == opEQ && isNull(e.right) {
+		e = Predicate{left: e.left, op: opIsNull}
+	}
 	if err := s.buildSubExpr(e.left); err != nil {
 		return err
 	}
 
+	// IS NULL 这类运算符没有右边的表达式
+	if e.right == nil {
+		s.builder.WriteString(" ")
+		s.builder.WriteString(e.op.String())
+		return nil
+	}
 	s.Margin(e.op.String())
 
 	if err := s.buildSubExpr(e.right); err != nil {
@@ -108,14 +357,34 @@ func (s *SQLBuilder) buildExpression(e any) error {
 
 	switch expr := e.(type) {
 	case Column:
-		return s.buildColumn(expr.name)
+		return s.buildTableColumn(expr)
 	case Value:
 		s.builder.WriteString("?")
-		s.args = append(s.args, expr.val)
+		return s.addArg(expr.val)
+	case valueList:
+		s.builder.WriteString("(")
+		for i, v := range expr.vals {
+			if i > 0 {
+				s.Comma()
+			}
+			s.builder.WriteString("?")
+			if err := s.addArg(v); err != nil {
+				return err
+			}
+		}
+		s.builder.WriteString(")")
 	case Predicate:
 		return s.buildPredicate(expr)
 	case Aggregate:
 		return s.buildAggregate(expr, false)
+	case WindowFunc:
+		return s.buildWindowFunc(expr, false)
+	case CaseExpr:
+		return s.buildCase(expr, false)
+	case JSONPathExpr:
+		return s.buildJSONPath(expr, false)
+	case SelectAlias:
+		return s.buildSelectAlias(expr)
 	case RawExpr:
 		return s.buildRawExpr(expr)
 	default:
@@ -135,6 +404,81 @@ func (s *SQLBuilder) buildAggregate(a Aggregate, useAlisa bool) error {
 	return nil
 }
 
+func (s *SQLBuilder) buildWindowFunc(w WindowFunc, useAlias bool) error {
+	s.builder.WriteString(w.fn)
+	s.builder.WriteString("(")
+	for i, arg := range w.args {
+		if i > 0 {
+			s.Comma()
+		}
+		if err := s.buildExpression(arg); err != nil {
+			return err
+		}
+	}
+	s.builder.WriteString(") OVER (")
+	if err := s.buildWindow(w.window); err != nil {
+		return err
+	}
+	s.builder.WriteString(")")
+	if useAlias {
+		s.As(w.alias)
+	}
+	return nil
+}
+
+func (s *SQLBuilder) buildWindow(w Window) error {
+	if len(w.partitionBy) > 0 {
+		s.builder.WriteString("PARTITION BY ")
+		for i, c := range w.partitionBy {
+			if i > 0 {
+				s.Comma()
+			}
+			if err := s.buildColumn(c); err != nil {
+				return err
+			}
+		}
+	}
+	if len(w.orderBy) > 0 {
+		if len(w.partitionBy) > 0 {
+			s.builder.WriteString(" ")
+		}
+		s.builder.WriteString(SQLOrderBy)
+		s.builder.WriteString(" ")
+		if err := s.buildOrderByItems(w.orderBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLBuilder) buildCase(c CaseExpr, useAlias bool) error {
+	if len(c.whens) == 0 {
+		return errs.ErrEmptyCase
+	}
+	s.builder.WriteString("CASE")
+	for _, w := range c.whens {
+		s.Margin("WHEN")
+		if err := s.buildPredicate(w.when); err != nil {
+			return err
+		}
+		s.Margin("THEN")
+		if err := s.buildExpression(w.then); err != nil {
+			return err
+		}
+	}
+	if c.els != nil {
+		s.Margin("ELSE")
+		if err := s.buildExpression(c.els); err != nil {
+			return err
+		}
+	}
+	s.builder.WriteString(" END")
+	if useAlias {
+		s.As(c.alias)
+	}
+	return nil
+}
+
 func (s *SQLBuilder) buildAssignment(assign Assignment) error {
 	if err := s.buildColumn(assign.column); err != nil {
 		return err
@@ -143,14 +487,92 @@ func (s *SQLBuilder) buildAssignment(assign Assignment) error {
 	return s.buildExpression(assign.val)
 }
 
+func (s *SQLBuilder) buildOrderBy(os []OrderBy) error {
+	if len(os) != 0 {
+		s.Margin(SQLOrderBy)
+		return s.buildOrderByItems(os)
+	}
+	return nil
+}
+
+func (s *SQLBuilder) buildOrderByItems(os []OrderBy) error {
+	for i, o := range os {
+		if i > 0 {
+			s.Comma()
+		}
+		if err := s.dialect.BuildOrderBy(s, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildOrderByTarget 构造排序项中的字段或者表达式部分
+func (s *SQLBuilder) buildOrderByTarget(o OrderBy) error {
+	if o.expr != nil {
+		return s.buildExpression(o.expr)
+	}
+	return s.buildColumn(o.col)
+}
+
+func (s *SQLBuilder) buildSelectAlias(a SelectAlias) error {
+	for _, sel := range s.selectList {
+		if selectableAlias(sel) == a.name {
+			s.Quota(a.name)
+			return nil
+		}
+	}
+	return errs.NewErrUnknownAlias(a.name)
+}
+
+// selectableAlias 返回 SELECT 列表中某一项的别名，没有别名返回空字符串
+func selectableAlias(sel Selectable) string {
+	switch col := sel.(type) {
+	case Column:
+		return col.alias
+	case Aggregate:
+		return col.alias
+	case WindowFunc:
+		return col.alias
+	case CaseExpr:
+		return col.alias
+	case JSONPathExpr:
+		return col.alias
+	}
+	return ""
+}
+
+func (s *SQLBuilder) buildLimitOffset(limit, offset int) {
+	if limit > 0 {
+		s.Margin(SQLLimit)
+		s.builder.WriteString("?")
+		s.AddArgs(limit)
+	}
+
+	if offset > 0 {
+		s.Margin(SQLOffset)
+		s.builder.WriteString("?")
+		s.AddArgs(offset)
+	}
+}
+
 func (s *SQLBuilder) buildRawExpr(raw RawExpr) error {
 	s.builder.WriteString(raw.raw)
-	if len(raw.args) != 0 {
-		s.AddArgs(raw.args...)
+	for _, arg := range raw.args {
+		if err := s.addArg(arg); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// reset 清空上一次 Build 的结果，
+// 中间件可能会先调用一次 Build，所以 Build 必须可以重复调用
+func (s *SQLBuilder) reset() {
+	s.builder.Reset()
+	s.args = nil
+}
+
 func (s *SQLBuilder) string() string {
 	s.builder.WriteString(";")
 	return s.builder.String()
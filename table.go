@@ -14,20 +14,87 @@ func (t Table) tableAlias() string {
 	return t.alias
 }
 
+// TableOf entity 必须是结构体指针，例如 TableOf(&User{})
 func TableOf(entity any) Table {
-	return Table{}
+	return Table{
+		entity: entity,
+	}
+}
+
+func (t Table) As(alias string) Table {
+	return Table{
+		entity: t.entity,
+		alias:  alias,
+	}
 }
 
-func (t Table) Join() *JoinBuilder {
+// C 引用这张表的列，构造 SQL 的时候会带上表名或者别名
+func (t Table) C(name string) Column {
+	return Column{
+		name:  name,
+		table: t,
+	}
+}
+
+func (t Table) Join(right TableReference) *JoinBuilder {
 	return &JoinBuilder{
 		left:  t,
 		typ:   "JOIN",
-		right: Join{},
+		right: right,
+	}
+}
+
+func (t Table) LeftJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  t,
+		typ:   "LEFT JOIN",
+		right: right,
+	}
+}
+
+func (t Table) RightJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  t,
+		typ:   "RIGHT JOIN",
+		right: right,
 	}
 }
 
 // Join Join查询
 type Join struct {
+	left  TableReference
+	typ   string
+	right TableReference
+	on    []Predicate
+	using []string
+}
+
+func (j Join) tableAlias() string {
+	return ""
+}
+
+func (j Join) Join(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  j,
+		typ:   "JOIN",
+		right: right,
+	}
+}
+
+func (j Join) LeftJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  j,
+		typ:   "LEFT JOIN",
+		right: right,
+	}
+}
+
+func (j Join) RightJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  j,
+		typ:   "RIGHT JOIN",
+		right: right,
+	}
 }
 
 type JoinBuilder struct {
@@ -36,8 +103,23 @@ type JoinBuilder struct {
 	right TableReference
 }
 
-func (j Join) tableAlias() string {
-	return ""
+func (j *JoinBuilder) On(ps ...Predicate) Join {
+	return Join{
+		left:  j.left,
+		typ:   j.typ,
+		right: j.right,
+		on:    ps,
+	}
+}
+
+// Using 参数是字段名
+func (j *JoinBuilder) Using(cols ...string) Join {
+	return Join{
+		left:  j.left,
+		typ:   j.typ,
+		right: j.right,
+		using: cols,
+	}
 }
 
 // SubQuery 子查询
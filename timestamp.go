@@ -0,0 +1,34 @@
+package toyorm
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"github.com/aristletl/toyorm/internal/model"
+)
+
+var (
+	nullTimeType   = reflect.TypeOf(sql.NullTime{})
+	nullOfTimeType = reflect.TypeOf(Null[time.Time]{})
+)
+
+// autoTimeValue 把 now 转换成 autoCreateTime 或者 autoUpdateTime 字段的类型
+func autoTimeValue(fd *model.Field, now time.Time) any {
+	var v any
+	switch {
+	case fd.TimeUnit == model.TimeUnitMilli:
+		v = now.UnixMilli()
+	case fd.TimeUnit == model.TimeUnitSecond:
+		v = now.Unix()
+	case fd.Type.Kind() == reflect.Pointer:
+		return &now
+	case fd.Type == nullTimeType:
+		return sql.NullTime{Time: now, Valid: true}
+	case fd.Type == nullOfTimeType:
+		return NullOf(now)
+	default:
+		return now
+	}
+	return reflect.ValueOf(v).Convert(fd.Type).Interface()
+}
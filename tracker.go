@@ -0,0 +1,134 @@
+package toyorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+
+	"github.com/aristletl/toyorm/internal/model"
+	"github.com/aristletl/toyorm/internal/valuer"
+)
+
+// Tracker 记录查询出来的实体在加载时的字段值，
+// Updater 据此只更新修改过的字段。
+// Tracker 会一直持有被记录的实体，一般在一次请求或者一个事务里面使用，
+// 用完之后丢弃，或者通过 Forget 移除不再需要的实体
+type Tracker struct {
+	mu        sync.RWMutex
+	snapshots map[any]map[string]any
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		snapshots: make(map[any]map[string]any, 8),
+	}
+}
+
+// Forget 不再记录 entity
+func (t *Tracker) Forget(entity any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.snapshots, entity)
+}
+
+// track 记录 entity 所有字段的值
+func (t *Tracker) track(entity any, val valuer.Value, m *model.Model) error {
+	return t.refresh(entity, val, m.Columns)
+}
+
+// refresh 更新 entity 部分字段的记录，entity 之前没有被记录的时候开始记录
+func (t *Tracker) refresh(entity any, val valuer.Value, fields []*model.Field) error {
+	values := make(map[string]any, len(fields))
+	for _, fd := range fields {
+		fdVal, err := val.Field(fd.Index)
+		if err != nil {
+			return err
+		}
+		if values[fd.Name], err = snapshotValue(fd, fdVal); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot, ok := t.snapshots[entity]
+	if !ok {
+		t.snapshots[entity] = values
+		return nil
+	}
+	for name, v := range values {
+		snapshot[name] = v
+	}
+	return nil
+}
+
+// changed 返回 entity 加载之后修改过的字段，
+// entity 没有被记录的时候 ok 为 false
+func (t *Tracker) changed(entity any, val valuer.Value, fields []*model.Field) (res []*model.Field, ok bool, err error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	snapshot, ok := t.snapshots[entity]
+	if !ok {
+		return nil, false, nil
+	}
+	for _, fd := range fields {
+		fdVal, err := val.Field(fd.Index)
+		if err != nil {
+			return nil, true, err
+		}
+		cur, err := snapshotValue(fd, fdVal)
+		if err != nil {
+			return nil, true, err
+		}
+		if old, tracked := snapshot[fd.Name]; !tracked || !reflect.DeepEqual(old, cur) {
+			res = append(res, fd)
+		}
+	}
+	return res, true, nil
+}
+
+// snapshotValue 记录字段写入数据库的值：注册了 Converter 的类型使用 ToDB 的结果，
+// 实现了 driver.Valuer 的类型使用 Value 的结果，
+// 这样 JSONColumn[map[string]any] 这种包含 map 或者切片的字段原地修改之后也能发现。
+// 其它类型复制指针指向的值和切片，否则之后通过指针修改的时候快照也跟着变了
+func snapshotValue(fd *model.Field, v any) (any, error) {
+	rv := reflect.ValueOf(v)
+	if v == nil || rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	if fd.Converter != nil {
+		if fd.Type.Kind() == reflect.Pointer {
+			v = rv.Elem().Interface()
+		}
+		res, err := fd.Converter.ToDB(v)
+		return copyValue(res), err
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		res, err := valuer.Value()
+		return copyValue(res), err
+	}
+	return copyValue(v), nil
+}
+
+// copyValue 复制指针指向的值和切片
+func copyValue(v any) any {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return v
+		}
+		cp := reflect.New(rv.Elem().Type())
+		cp.Elem().Set(rv.Elem())
+		return cp.Interface()
+	case reflect.Slice:
+		if rv.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		reflect.Copy(cp, rv)
+		return cp.Interface()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,117 @@
+package toyorm
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	ctx := context.Background()
+	tr := NewTracker()
+
+	cols := []string{"id", "first_name", "age", "last_name"}
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		sqlmock.NewRows(cols).AddRow(1, "Tom", 18, "Jerry"))
+	tm, err := NewSelector[TestModel](db).Track(tr).Where(Col("Id").EQ(1)).Get(ctx)
+	require.NoError(t, err)
+
+	// 没有修改，不执行任何语句
+	res := NewUpdater[TestModel](db).Track(tr).Update(tm).Exec(ctx)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	// 只更新修改过的字段，通过指针修改也能识别
+	tm.Age = 19
+	tm.LastName.String = "Mouse"
+	mock.ExpectExec("UPDATE `test_model` SET `age`=\\?, `last_name`=\\? WHERE `id` = \\?;").
+		WithArgs(int8(19), &sql.NullString{String: "Mouse", Valid: true}, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res = NewUpdater[TestModel](db).Track(tr).Update(tm).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	// 更新成功之后刷新记录
+	res = NewUpdater[TestModel](db).Track(tr).Update(tm).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	// Omit 的字段没有写入数据库，下一次仍然需要更新
+	tm.FirstName = "Tim"
+	tm.Age = 20
+	mock.ExpectExec("UPDATE `test_model` SET `age`=\\? WHERE `id` = \\?;").
+		WithArgs(int8(20), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res = NewUpdater[TestModel](db).Track(tr).Update(tm).Omit("FirstName").Exec(ctx)
+	require.NoError(t, res.Err())
+	mock.ExpectExec("UPDATE `test_model` SET `first_name`=\\? WHERE `id` = \\?;").
+		WithArgs("Tim", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res = NewUpdater[TestModel](db).Track(tr).Update(tm).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	// GetMulti 查询出来的实体同样会被记录
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		sqlmock.NewRows(cols).AddRow(2, "a", 1, nil).AddRow(3, "b", 2, nil))
+	tms, err := NewSelector[TestModel](db).Track(tr).GetMulti(ctx)
+	require.NoError(t, err)
+	tms[1].FirstName = "c"
+	res = NewUpdater[TestModel](db).Track(tr).Update(tms[0]).Exec(ctx)
+	require.NoError(t, res.Err())
+	mock.ExpectExec("UPDATE `test_model` SET `first_name`=\\? WHERE `id` = \\?;").
+		WithArgs("c", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res = NewUpdater[TestModel](db).Track(tr).Update(tms[1]).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	// 不再记录之后更新所有字段
+	tr.Forget(tms[1])
+	mock.ExpectExec("UPDATE `test_model` SET `first_name`=\\?, `age`=\\?, `last_name`=\\? WHERE `id` = \\?;").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res = NewUpdater[TestModel](db).Track(tr).Update(tms[1]).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// map 和切片原地修改的时候也能发现
+func TestTracker_InPlace(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	ctx := context.Background()
+	tr := NewTracker()
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "attrs"}).AddRow(1, `{"color":"red"}`))
+	doc, err := NewSelector[TrackedDoc](db).Track(tr).Where(Col("Id").EQ(1)).Get(ctx)
+	require.NoError(t, err)
+
+	res := NewUpdater[TrackedDoc](db).Track(tr).Update(doc).Exec(ctx)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	doc.Attrs.Val["color"] = "blue"
+	mock.ExpectExec("UPDATE `tracked_doc` SET `attrs`=\\? WHERE `id` = \\?;").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res = NewUpdater[TrackedDoc](db).Track(tr).Update(doc).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type TrackedDoc struct {
+	Id    int64
+	Attrs JSONColumn[map[string]any]
+}
@@ -3,8 +3,10 @@ package toyorm
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/aristletl/toyorm/internal/model"
+	"github.com/aristletl/toyorm/internal/valuer"
 )
 
 type Tx struct {
@@ -50,4 +52,8 @@ type core struct {
 	r       *model.Registry
 	ms      []Middleware
 	dialect Dialect
+	// clock 自动维护的时间字段使用的时钟
+	clock func() time.Time
+	// valCreator 访问结构体字段的方式，默认使用 toyorm-gen 生成的代码，没有的话使用 unsafe 的实现
+	valCreator valuer.Creator
 }
@@ -12,12 +12,22 @@ const (
 	SQLLimit   = "LIMIT"
 	SQLOffset  = "OFFSET"
 
+	SQLWith      = "WITH"
+	SQLRecursive = "RECURSIVE"
+
+	SQLUnion     = "UNION"
+	SQLUnionAll  = "UNION ALL"
+	SQLIntersect = "INTERSECT"
+	SQLExcept    = "EXCEPT"
+
 	SQLInsert = "INSERT"
 	SQLInto   = "INTO"
 	SQLValues = "VALUES"
 
 	SQLUpdate = "UPDATE "
 	SQLSet    = "SET"
+
+	SQLDelete = "DELETE"
 )
 
 type Executor interface {
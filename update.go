@@ -2,7 +2,12 @@ package toyorm
 
 import (
 	"context"
+	"reflect"
+
+	"go.uber.org/multierr"
+
 	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/aristletl/toyorm/internal/model"
 	"github.com/aristletl/toyorm/internal/valuer"
 )
 
@@ -13,51 +18,250 @@ type Updater[T any] struct {
 	val        *T
 	assigns    []Assignable
 	where      []Predicate
+
+	// nonZero 没有调用 Set 的时候只更新非零值的字段
+	nonZero bool
+	// omits 没有调用 Set 的时候不更新的字段
+	omits []string
+
+	tracker *Tracker
+	// unscoped 同时更新已经软删除的数据
+	unscoped bool
 }
 
+// Exec 使用了 Track 并且实体没有任何修改的时候，不会执行任何语句。
+// 模型有版本号字段的时候，更新成功之后把 Update 传入的实体的版本号加一，
+// 没有更新任何行说明数据已经被其它人修改了，返回 errs.ErrStaleObject。
+// 通过 Update 传入了实体的时候会调用实体的 BeforeUpdate 和 AfterUpdate 钩子
 func (u *Updater[T]) Exec(ctx context.Context) Result {
-	q, err := u.Build()
+	if u.val == nil {
+		return exec(ctx, u.sess, u, SQLUpdate)
+	}
+	vals := []*T{u.val}
+	if err := runHooks(ctx, vals, BeforeUpdateHook.BeforeUpdate); err != nil {
+		return Result{err: err}
+	}
+	res := u.execEntity(ctx)
+	if res.err == nil {
+		res.err = runHooks(ctx, vals, AfterUpdateHook.AfterUpdate)
+	}
+	return res
+}
+
+// execEntity 更新 Update 传入的实体
+func (u *Updater[T]) execEntity(ctx context.Context) Result {
+
+	var (
+		err    error
+		t      T
+		fields []*model.Field
+	)
+	u.model, err = u.r.Get(&t)
 	if err != nil {
-		return Result{
-			err: err,
+		return Result{err: err}
+	}
+	val := u.valCreator(u.val, u.model)
+	if u.tracker != nil && len(u.assigns) == 0 {
+		if fields, err = u.entityFields(val); err != nil {
+			return Result{err: err}
+		}
+		if len(fields) == 0 {
+			return Result{res: rowsResult{}}
+		}
+	}
+
+	res := exec(ctx, u.sess, u, SQLUpdate)
+	if res.err != nil {
+		return res
+	}
+	if version := u.versionField(); version != nil {
+		if res.err = u.bumpVersion(res, val, version); res.err != nil {
+			return res
 		}
+		fields = append(fields, version)
+	}
+	if u.tracker != nil && len(fields) != 0 {
+		fields = append(fields, u.updateTimeFields()...)
+		res.err = u.tracker.refresh(u.val, val, fields)
 	}
-	res := Result{}
-	res.res, res.err = u.sess.execContext(ctx, q.SQL, q.Args...)
 	return res
 }
 
+// bumpVersion 检查是否更新成功，并且把实体的版本号加一
+func (u *Updater[T]) bumpVersion(res Result, val valuer.Value, version *model.Field) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errs.ErrStaleObject
+	}
+	cur, err := val.Field(version.Index)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(cur)
+	if rv.CanInt() {
+		return val.SetField(version.Index, rv.Int()+1)
+	}
+	return val.SetField(version.Index, rv.Uint()+1)
+}
+
+// versionField 需要使用乐观锁的时候返回版本号字段。
+// 只有通过 Update 传入了实体才能拿到旧的版本号，
+// 通过 Set 显式更新版本号的时候由用户自己控制
+func (u *Updater[T]) versionField() *model.Field {
+	version := u.model.Version
+	if version == nil || u.val == nil {
+		return nil
+	}
+	if u.assigned(version.Name) {
+		return nil
+	}
+	return version
+}
+
+// validate 校验 SET 里面的字段，使用实体的值或者 Assign 传入的值，
+// 传入了实体的时候还会调用 Validator
+func (u *Updater[T]) validate(val valuer.Value, assigns []Assignable) error {
+	var (
+		err    error
+		fields []*model.Field
+	)
+	for _, assign := range assigns {
+		switch expr := assign.(type) {
+		case Column:
+			if fd, ok := u.model.FieldMap[expr.name]; ok {
+				fields = append(fields, fd)
+			}
+		case Assignment:
+			fd, ok := u.model.FieldMap[expr.column]
+			if v, isVal := expr.val.(Value); ok && isVal {
+				err = multierr.Append(err, validateField(fd, v.val))
+			}
+		}
+	}
+	if val != nil {
+		err = multierr.Append(err, validateEntity(u.val, val, fields))
+	}
+	return err
+}
+
+// updateTimeFields 需要自动写入当前时间的 autoUpdateTime 字段，
+// 通过 Set 显式更新或者被 Omit 的字段由用户自己控制
+func (u *Updater[T]) updateTimeFields() []*model.Field {
+	var res []*model.Field
+	for _, fd := range u.model.Columns {
+		if !fd.AutoUpdateTime || u.assigned(fd.Name) {
+			continue
+		}
+		if len(u.assigns) == 0 && u.omitted(fd.Name) {
+			continue
+		}
+		res = append(res, fd)
+	}
+	return res
+}
+
+// assigned 是否通过 Set 更新了字段
+func (u *Updater[T]) assigned(name string) bool {
+	for _, assign := range u.assigns {
+		switch expr := assign.(type) {
+		case Column:
+			if expr.name == name {
+				return true
+			}
+		case Assignment:
+			if expr.column == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (u *Updater[T]) omitted(name string) bool {
+	for _, omit := range u.omits {
+		if omit == name {
+			return true
+		}
+	}
+	return false
+}
+
 func NewUpdater[T any](sess Session) *Updater[T] {
 	c := sess.getCore()
 	return &Updater[T]{
+		sess: sess,
 		SQLBuilder: SQLBuilder{
 			core: c,
 		},
-		valCreator: valuer.NewUnsafeValue,
+		valCreator: c.valCreator,
 	}
 }
 
 func (u *Updater[T]) Build() (*Query, error) {
-	if len(u.assigns) == 0 {
-		return nil, errs.ErrNoUpdatedColumns
+	var (
+		err error
+		t   T
+	)
+	u.reset()
+	u.model, err = u.r.Get(&t)
+	if err != nil {
+		return nil, err
 	}
 
-	var err error
-	u.builder.WriteString(SQLUpdate)
-	u.model, err = u.r.Get(u.val)
-	if err != nil {
+	var val valuer.Value
+	if u.val != nil {
+		val = u.valCreator(u.val, u.model)
+	}
+	assigns := u.assigns
+	if len(assigns) == 0 && val != nil {
+		fields, err := u.entityFields(val)
+		if err != nil {
+			return nil, err
+		}
+		assigns = make([]Assignable, 0, len(fields))
+		for _, fd := range fields {
+			assigns = append(assigns, Col(fd.Name))
+		}
+	}
+	if len(assigns) == 0 {
+		return nil, errs.ErrNoUpdatedColumns
+	}
+	if err = u.validate(val, assigns); err != nil {
 		return nil, err
 	}
+
+	u.builder.WriteString(SQLUpdate)
 	u.Quota(u.model.TableName)
 
+	assigns = assigns[:len(assigns):len(assigns)]
+	now := u.clock()
+	for _, fd := range u.updateTimeFields() {
+		ts := autoTimeValue(fd, now)
+		if val != nil {
+			if err = val.SetField(fd.Index, ts); err != nil {
+				return nil, err
+			}
+		}
+		assigns = append(assigns, Assign(fd.Name, ts))
+	}
+	version := u.versionField()
+	if version != nil {
+		assigns = append(assigns, Assign(version.Name, Col(version.Name).Add(1)))
+	}
+
 	u.Margin(SQLSet)
-	val := u.valCreator(u.val, u.model)
-	for i, assign := range u.assigns {
+	for i, assign := range assigns {
 		if i > 0 {
 			u.Comma()
 		}
 		switch expr := assign.(type) {
 		case Column:
+			if val == nil {
+				return nil, errs.ErrNoUpdatedColumns
+			}
 			arg, err := val.FieldByName(expr.name)
 			if err != nil {
 				return nil, err
@@ -66,7 +270,9 @@ func (u *Updater[T]) Build() (*Query, error) {
 				return nil, err
 			}
 			u.builder.WriteString("=?")
-			u.AddArgs(arg)
+			if err = u.addArg(arg); err != nil {
+				return nil, err
+			}
 		case Assignment:
 			if err = u.buildAssignment(expr); err != nil {
 				return nil, err
@@ -74,9 +280,26 @@ func (u *Updater[T]) Build() (*Query, error) {
 		}
 	}
 
-	if len(u.where) != 0 {
+	// 显式调用了 Set 的时候实体只提供列的值，更新哪些行由 Where 决定
+	where := u.where
+	if len(where) == 0 && val != nil && len(u.assigns) == 0 {
+		if where, err = entityWhere(u.model, val); err != nil {
+			return nil, err
+		}
+	}
+	if !u.unscoped {
+		where = append(where[:len(where):len(where)], u.softDeletePredicates(nil, u.model)...)
+	}
+	if version != nil {
+		cur, err := val.Field(version.Index)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where[:len(where):len(where)], Col(version.Name).EQ(cur))
+	}
+	if len(where) != 0 {
 		u.Margin(SQLWhere)
-		if err = u.buildPredicates(u.where); err != nil {
+		if err = u.buildPredicates(where); err != nil {
 			return nil, err
 		}
 	}
@@ -87,11 +310,101 @@ func (u *Updater[T]) Build() (*Query, error) {
 	}, nil
 }
 
+// entityFields 没有调用 Set 的时候，更新除了主键、版本号、时间字段、软删除字段和 Omit 之外的字段，
+// 调用了 UpdateNonZero 的时候只更新其中非零值的字段，
+// 调用了 Track 并且实体被记录过的时候只更新其中修改过的字段
+func (u *Updater[T]) entityFields(val valuer.Value) ([]*model.Field, error) {
+	omits := make(map[string]struct{}, len(u.omits))
+	for _, name := range u.omits {
+		if _, ok := u.model.FieldMap[name]; !ok {
+			return nil, errs.NewErrUnknownField(name)
+		}
+		omits[name] = struct{}{}
+	}
+	res := make([]*model.Field, 0, len(u.model.Columns))
+	for _, fd := range u.model.Columns {
+		if _, ok := omits[fd.Name]; ok || fd.PrimaryKey || fd.Version ||
+			fd.AutoCreateTime || fd.AutoUpdateTime || fd.SoftDelete {
+			continue
+		}
+		if u.nonZero {
+			fdVal, err := val.Field(fd.Index)
+			if err != nil {
+				return nil, err
+			}
+			if isZero(fd, fdVal) {
+				continue
+			}
+		}
+		res = append(res, fd)
+	}
+	if u.tracker == nil {
+		return res, nil
+	}
+	changed, ok, err := u.tracker.changed(u.val, val, res)
+	if err != nil || !ok {
+		return res, err
+	}
+	return changed, nil
+}
+
+// entityWhere 按照实体的主键构造条件，
+// Updater 在传入了实体但是没有调用 Set 和 Where 的时候使用，
+// Deleter 在传入了实体但是没有调用 Where 的时候使用，避免不小心影响整张表。
+// 主键都是零值的时候不会匹配任何行，返回 errs.ErrZeroPrimaryKey
+func entityWhere(m *model.Model, val valuer.Value) ([]Predicate, error) {
+	pks := make([]any, 0, len(m.PrimaryKeys))
+	zero := true
+	for _, pk := range m.PrimaryKeys {
+		pkVal, err := val.Field(pk.Index)
+		if err != nil {
+			return nil, err
+		}
+		zero = zero && isZero(pk, pkVal)
+		pks = append(pks, pkVal)
+	}
+	if len(pks) != 0 && zero {
+		return nil, errs.ErrZeroPrimaryKey
+	}
+	p, err := pkPredicate(m, pks)
+	if err != nil {
+		return nil, err
+	}
+	return []Predicate{p}, nil
+}
+
+// Update 指定要更新的结构体。没有调用 Set 的时候更新所有非主键字段，
+// 这时候如果也没有调用 Where 就按照主键更新
 func (u *Updater[T]) Update(t *T) *Updater[T] {
 	u.val = t
 	return u
 }
 
+// UpdateNonZero 没有调用 Set 的时候只更新非零值的字段
+func (u *Updater[T]) UpdateNonZero() *Updater[T] {
+	u.nonZero = true
+	return u
+}
+
+// Track 只更新 Update 传入的实体在查询之后修改过的字段，
+// 实体需要通过使用同一个 tr 的 Selector 查询出来，否则和没有调用 Track 一样
+func (u *Updater[T]) Track(tr *Tracker) *Updater[T] {
+	u.tracker = tr
+	return u
+}
+
+// Unscoped 同时更新已经软删除的数据
+func (u *Updater[T]) Unscoped() *Updater[T] {
+	u.unscoped = true
+	return u
+}
+
+// Omit 没有调用 Set 的时候不更新这些字段
+func (u *Updater[T]) Omit(fields ...string) *Updater[T] {
+	u.omits = fields
+	return u
+}
+
 func (u *Updater[T]) Set(assigns ...Assignable) *Updater[T] {
 	u.assigns = assigns
 	return u
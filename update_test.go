@@ -1,9 +1,15 @@
 package toyorm
 
 import (
-	"github.com/aristletl/toyorm/internal/errs"
-	"github.com/stretchr/testify/assert"
+	"context"
 	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aristletl/toyorm/internal/errs"
 )
 
 func TestUpdater_Build(t *testing.T) {
@@ -74,14 +80,169 @@ func TestUpdater_Build(t *testing.T) {
 				Args: []any{1},
 			},
 		},
-		//{
-		//	name: "non-zero",
-		//	u: NewUpdater[TestModel](db).Set(AssignNotZeroColumns(&TestModel{Id: 13})...),
-		//	want: &Query{
-		//		SQL:  "UPDATE `test_model` SET `id`=?;",
-		//		Args: []any{int64(13)},
-		//	},
-		//},
+		{
+			// 显式调用了 Set，实体只提供列的值，不会按照主键加上 WHERE
+			name: "set with entity primary key",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id:  13,
+				Age: 18,
+			}).Set(Col("Age")),
+			want: &Query{
+				SQL:  "UPDATE `test_model` SET `age`=?;",
+				Args: []any{int8(18)},
+			},
+		},
+		{
+			// 主键是零值，按照主键更新不会匹配任何行
+			name: "entity zero primary key",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				FirstName: "Tom",
+			}),
+			wantErr: errs.ErrZeroPrimaryKey,
+		},
+		{
+			name: "entity",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id:        13,
+				FirstName: "Tom",
+			}),
+			want: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=?, `age`=?, `last_name`=? WHERE `id` = ?;",
+				Args: []any{"Tom", int8(0), nil, int64(13)},
+			},
+		},
+		{
+			name: "non-zero",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id:        13,
+				FirstName: "Tom",
+			}).UpdateNonZero(),
+			want: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=? WHERE `id` = ?;",
+				Args: []any{"Tom", int64(13)},
+			},
+		},
+		{
+			name: "non-zero omit",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id:        13,
+				FirstName: "Tom",
+				Age:       18,
+			}).UpdateNonZero().Omit("FirstName"),
+			want: &Query{
+				SQL:  "UPDATE `test_model` SET `age`=? WHERE `id` = ?;",
+				Args: []any{int8(18), int64(13)},
+			},
+		},
+		{
+			name: "omit",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id:  13,
+				Age: 18,
+			}).Omit("FirstName", "LastName"),
+			want: &Query{
+				SQL:  "UPDATE `test_model` SET `age`=? WHERE `id` = ?;",
+				Args: []any{int8(18), int64(13)},
+			},
+		},
+		{
+			name: "omit unknown field",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id: 13,
+			}).Omit("first_name"),
+			wantErr: errs.NewErrUnknownField("first_name"),
+		},
+		{
+			name: "non-zero nothing changed",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id: 13,
+			}).UpdateNonZero(),
+			wantErr: errs.ErrNoUpdatedColumns,
+		},
+		{
+			name: "composite primary key",
+			u: NewUpdater[OrderItem](db).Update(&OrderItem{
+				OrderId: 1,
+				ItemId:  2,
+				Qty:     3,
+			}).UpdateNonZero(),
+			want: &Query{
+				SQL:  "UPDATE `order_item` SET `qty`=? WHERE (`order_id` = ?) AND (`item_id` = ?);",
+				Args: []any{3, int64(1), int64(2)},
+			},
+		},
+		{
+			name: "version",
+			u: NewUpdater[VersionModel](db).Update(&VersionModel{
+				Id:      13,
+				Name:    "Tom",
+				Version: 3,
+			}),
+			want: &Query{
+				SQL:  "UPDATE `version_model` SET `name`=?, `version`=`version` + ? WHERE (`id` = ?) AND (`version` = ?);",
+				Args: []any{"Tom", 1, int64(13), int32(3)},
+			},
+		},
+		{
+			name: "version with where",
+			u: NewUpdater[VersionModel](db).Update(&VersionModel{
+				Name:    "Tom",
+				Version: 3,
+			}).Set(Col("Name")).Where(Col("Name").EQ("Jerry")),
+			want: &Query{
+				SQL:  "UPDATE `version_model` SET `name`=?, `version`=`version` + ? WHERE (`name` = ?) AND (`version` = ?);",
+				Args: []any{"Tom", 1, "Jerry", int32(3)},
+			},
+		},
+		{
+			// 显式更新版本号的时候不再使用乐观锁
+			name: "version set explicitly",
+			u: NewUpdater[VersionModel](db).Update(&VersionModel{
+				Id:      13,
+				Version: 3,
+			}).Set(Assign("Version", 0)).Where(Col("Id").EQ(13)),
+			want: &Query{
+				SQL:  "UPDATE `version_model` SET `version`=? WHERE `id` = ?;",
+				Args: []any{0, 13},
+			},
+		},
+		{
+			name: "version without entity",
+			u:    NewUpdater[VersionModel](db).Set(Assign("Name", "Tom")),
+			want: &Query{
+				SQL:  "UPDATE `version_model` SET `name`=?;",
+				Args: []any{"Tom"},
+			},
+		},
+		{
+			name: "soft delete",
+			u: NewUpdater[SoftModel](db).Update(&SoftModel{
+				Id:   13,
+				Name: "Tom",
+			}),
+			want: &Query{
+				SQL:  "UPDATE `soft_model` SET `name`=? WHERE (`id` = ?) AND (`deleted_at` IS NULL);",
+				Args: []any{"Tom", int64(13)},
+			},
+		},
+		{
+			name: "soft delete unscoped",
+			u:    NewUpdater[SoftModel](db).Set(Assign("Name", "Tom")).Unscoped(),
+			want: &Query{
+				SQL:  "UPDATE `soft_model` SET `name`=?;",
+				Args: []any{"Tom"},
+			},
+		},
+		{
+			name:    "multiple version",
+			u:       NewUpdater[MultipleVersionModel](db).Set(Assign("Name", "Tom")),
+			wantErr: errs.NewErrMultipleVersion("V1", "V2"),
+		},
+		{
+			name:    "invalid version type",
+			u:       NewUpdater[InvalidVersionModel](db).Set(Assign("Name", "Tom")),
+			wantErr: errs.NewErrInvalidVersionType("Version"),
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -94,3 +255,123 @@ func TestUpdater_Build(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdater_Exec(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	mock.ExpectExec("UPDATE `test_model` SET `first_name`=\\? WHERE `id` = \\?;").
+		WithArgs("Tom", int64(13)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res := NewUpdater[TestModel](db).Update(&TestModel{Id: 13, FirstName: "Tom"}).
+		UpdateNonZero().Exec(context.Background())
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdater_Exec_Version(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	vm := &VersionModel{Id: 13, Name: "Tom", Version: 3}
+	mock.ExpectExec("UPDATE `version_model` SET `name`=\\?, `version`=`version` \\+ \\? WHERE \\(`id` = \\?\\) AND \\(`version` = \\?\\);").
+		WithArgs("Tom", 1, int64(13), int32(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res := NewUpdater[VersionModel](db).Update(vm).Exec(ctx)
+	require.NoError(t, res.Err())
+	assert.Equal(t, int32(4), vm.Version)
+
+	// 版本号不一致，没有更新任何行
+	mock.ExpectExec("UPDATE .*").
+		WithArgs("Tom", 1, int64(13), int32(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	res = NewUpdater[VersionModel](db).Update(vm).Exec(ctx)
+	assert.Equal(t, errs.ErrStaleObject, res.Err())
+	assert.Equal(t, int32(4), vm.Version)
+
+	// 没有修改的时候不执行，版本号也不变
+	tr := NewTracker()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "version"}).AddRow(13, "Tom", 4))
+	vm, err = NewSelector[VersionModel](db).Track(tr).Get(ctx)
+	require.NoError(t, err)
+	res = NewUpdater[VersionModel](db).Track(tr).Update(vm).Exec(ctx)
+	require.NoError(t, res.Err())
+	assert.Equal(t, int32(4), vm.Version)
+
+	// 更新之后的版本号也会被记录，不会被当成修改
+	vm.Name = "Jerry"
+	mock.ExpectExec("UPDATE .*").
+		WithArgs("Jerry", 1, int64(13), int32(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res = NewUpdater[VersionModel](db).Track(tr).Update(vm).Exec(ctx)
+	require.NoError(t, res.Err())
+	assert.Equal(t, int32(5), vm.Version)
+	res = NewUpdater[VersionModel](db).Track(tr).Update(vm).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type VersionModel struct {
+	Id      int64
+	Name    string
+	Version int32 `orm:"version"`
+}
+
+type MultipleVersionModel struct {
+	Id   int64
+	Name string
+	V1   int64 `orm:"version"`
+	V2   int64 `orm:"version"`
+}
+
+type InvalidVersionModel struct {
+	Id      int64
+	Name    string
+	Version string `orm:"version"`
+}
+
+func TestUpdater_Timestamps(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	db := memoryDB(t, DBWithClock(func() time.Time { return now }))
+	created := time.UnixMilli(1600000000000)
+
+	tm := &TimeModel{Id: 1, Name: "Tom", CreatedAt: created, CreatedUnix: 1600000000}
+	q, err := NewUpdater[TimeModel](db).Update(tm).Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL:  "UPDATE `time_model` SET `name`=?, `updated_at`=?, `updated_time`=? WHERE `id` = ?;",
+		Args: []any{"Tom", int64(1700000000123), &now, int64(1)},
+	}, q)
+	// 更新时间同时写回实体，创建时间不变
+	assert.Equal(t, int64(1700000000123), tm.UpdatedAt)
+	assert.Equal(t, &now, tm.UpdatedTime)
+	assert.Equal(t, created, tm.CreatedAt)
+
+	// 显式指定的值由用户自己控制
+	q, err = NewUpdater[TimeModel](db).Set(Assign("Name", "Tom"), Assign("UpdatedAt", 1)).
+		Where(Col("Id").EQ(1)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL:  "UPDATE `time_model` SET `name`=?, `updated_at`=?, `updated_time`=? WHERE `id` = ?;",
+		Args: []any{"Tom", 1, &now, 1},
+	}, q)
+
+	q, err = NewUpdater[TimeModel](db).Update(&TimeModel{Id: 1, Name: "Tom"}).
+		Omit("UpdatedTime").Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL:  "UPDATE `time_model` SET `name`=?, `updated_at`=? WHERE `id` = ?;",
+		Args: []any{"Tom", int64(1700000000123), int64(1)},
+	}, q)
+}
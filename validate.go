@@ -0,0 +1,144 @@
+package toyorm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+
+	"go.uber.org/multierr"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/aristletl/toyorm/internal/model"
+	"github.com/aristletl/toyorm/internal/valuer"
+)
+
+// Validator 实体可以实现这个接口提供自定义的校验规则。
+// Inserter 和 Updater 在构造 SQL 之前调用，返回的 error 会和标签的校验结果合并，
+// 可以通过 multierr.Errors 拿到每一个错误
+type Validator interface {
+	Validate() error
+}
+
+// validateEntity 按照标签校验 fields，再调用实体的 Validator，合并所有的错误
+func validateEntity(entity any, val valuer.Value, fields []*model.Field) error {
+	var err error
+	for _, fd := range fields {
+		if fd.Validation == nil {
+			continue
+		}
+		fdVal, e := val.Field(fd.Index)
+		if e != nil {
+			return e
+		}
+		err = multierr.Append(err, validateField(fd, fdVal))
+	}
+	if v, ok := entity.(Validator); ok {
+		err = multierr.Append(err, v.Validate())
+	}
+	return err
+}
+
+// validateField 按照标签校验字段的值
+func validateField(fd *model.Field, val any) error {
+	rule := fd.Validation
+	if rule == nil {
+		return nil
+	}
+	rv, err := validationValue(val)
+	if err != nil {
+		return err
+	}
+	if !rv.IsValid() {
+		if rule.NotNull {
+			return errs.NewErrFieldValidation(fd.Name, "不能为 NULL")
+		}
+		return nil
+	}
+
+	var res error
+	if rule.Size > 0 {
+		size := -1
+		switch {
+		case rv.Kind() == reflect.String:
+			size = utf8.RuneCountInString(rv.String())
+		case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+			size = rv.Len()
+		}
+		if size > rule.Size {
+			res = multierr.Append(res, errs.NewErrFieldValidation(fd.Name,
+				fmt.Sprintf("长度 %d 超过 %d", size, rule.Size)))
+		}
+	}
+	if num, ok := numberOf(rv); ok {
+		if rule.Min != nil && num < *rule.Min {
+			res = multierr.Append(res, errs.NewErrFieldValidation(fd.Name,
+				fmt.Sprintf("%v 小于 %v", num, *rule.Min)))
+		}
+		if rule.Max != nil && num > *rule.Max {
+			res = multierr.Append(res, errs.NewErrFieldValidation(fd.Name,
+				fmt.Sprintf("%v 大于 %v", num, *rule.Max)))
+		}
+	}
+	if str, ok := stringOf(rv); ok && rule.Regex != nil {
+		if !rule.Regex.MatchString(str) {
+			res = multierr.Append(res, errs.NewErrFieldValidation(fd.Name,
+				fmt.Sprintf("%q 不匹配 %s", str, rule.Regex)))
+		}
+	}
+	return res
+}
+
+// validationValue 解开指针和 driver.Valuer，返回实际写入数据库的值，
+// NULL 返回零值的 reflect.Value
+func validationValue(val any) (reflect.Value, error) {
+	for {
+		if val == nil {
+			return reflect.Value{}, nil
+		}
+		if v, ok := val.(driver.Valuer); ok {
+			rv := reflect.ValueOf(val)
+			if rv.Kind() == reflect.Pointer && rv.IsNil() {
+				return reflect.Value{}, nil
+			}
+			dv, err := v.Value()
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if dv == nil {
+				return reflect.Value{}, nil
+			}
+			return reflect.ValueOf(dv), nil
+		}
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Pointer {
+			return rv, nil
+		}
+		if rv.IsNil() {
+			return reflect.Value{}, nil
+		}
+		val = rv.Elem().Interface()
+	}
+}
+
+func numberOf(rv reflect.Value) (float64, bool) {
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func stringOf(rv reflect.Value) (string, bool) {
+	switch {
+	case rv.Kind() == reflect.String:
+		return rv.String(), true
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		return string(rv.Bytes()), true
+	}
+	return "", false
+}
@@ -0,0 +1,129 @@
+package toyorm
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
+
+	"github.com/aristletl/toyorm/internal/errs"
+)
+
+func TestValidate(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name    string
+		q       QueryBuilder
+		wantErr error
+	}{
+		{
+			name: "valid",
+			q: NewInserter[ValidatedModel](db).Values(&ValidatedModel{
+				Name:     "tom",
+				Nickname: &sql.NullString{String: "汤姆", Valid: true},
+				Age:      18,
+				Email:    "tom@example.com",
+			}),
+		},
+		{
+			name: "all fields",
+			q: NewInserter[ValidatedModel](db).Values(&ValidatedModel{
+				Name:  "tom_is_too_long",
+				Age:   151,
+				Email: "tom",
+			}),
+			wantErr: multierr.Combine(
+				errs.NewErrFieldValidation("Name", "长度 15 超过 8"),
+				errs.NewErrFieldValidation("Nickname", "不能为 NULL"),
+				errs.NewErrFieldValidation("Age", "151 大于 150"),
+				errs.NewErrFieldValidation("Email", `"tom" 不匹配 ^\S+@\S+$`),
+			),
+		},
+		{
+			// 通过 Valuer 拿到实际的值，size 按照字符计算
+			name: "valuer",
+			q: NewInserter[ValidatedModel](db).Values(&ValidatedModel{
+				Name:     "tom",
+				Nickname: &sql.NullString{String: "汤姆汤姆汤姆汤姆汤姆", Valid: false},
+				Age:      -1,
+				Email:    "tom@example.com",
+			}),
+			wantErr: multierr.Combine(
+				errs.NewErrFieldValidation("Nickname", "不能为 NULL"),
+				errs.NewErrFieldValidation("Age", "-1 小于 0"),
+			),
+		},
+		{
+			name: "validator",
+			q: NewInserter[ValidatedModel](db).Values(&ValidatedModel{
+				Name:     "admin",
+				Nickname: &sql.NullString{String: "admin", Valid: true},
+				Email:    "admin@example.com",
+			}),
+			wantErr: errReservedName,
+		},
+		{
+			// 只校验插入的列
+			name: "columns",
+			q: NewInserter[ValidatedModel](db).Columns("Name", "Age").Values(&ValidatedModel{
+				Name: "tom",
+				Age:  200,
+			}),
+			wantErr: errs.NewErrFieldValidation("Age", "200 大于 150"),
+		},
+		{
+			name:    "insert map",
+			q:       NewInserter[ValidatedModel](db).InsertMap(map[string]any{"Name": "tom", "Email": "tom"}),
+			wantErr: errs.NewErrFieldValidation("Email", `"tom" 不匹配 ^\S+@\S+$`),
+		},
+		{
+			name: "update entity",
+			q: NewUpdater[ValidatedModel](db).Update(&ValidatedModel{
+				Id:  1,
+				Age: 200,
+			}).Set(Col("Age")),
+			wantErr: errs.NewErrFieldValidation("Age", "200 大于 150"),
+		},
+		{
+			name:    "update assign",
+			q:       NewUpdater[ValidatedModel](db).Set(Assign("Name", "tom_is_too_long"), Assign("Age", Col("Age").Add(1))),
+			wantErr: errs.NewErrFieldValidation("Name", "长度 15 超过 8"),
+		},
+		{
+			name:    "invalid size",
+			q:       NewInserter[InvalidValidationModel](db).Values(&InvalidValidationModel{}),
+			wantErr: errs.NewErrInvalidTagContent("size=abc"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+var errReservedName = errors.New("保留的用户名")
+
+type ValidatedModel struct {
+	Id       int64
+	Name     string          `orm:"size=8"`
+	Nickname *sql.NullString `orm:"notnull,size=8"`
+	Age      int16           `orm:"min=0,max=150"`
+	Email    string          `orm:"regex=^\\S+@\\S+$"`
+}
+
+func (v *ValidatedModel) Validate() error {
+	if v.Name == "admin" {
+		return errReservedName
+	}
+	return nil
+}
+
+type InvalidValidationModel struct {
+	Id   int64
+	Name string `orm:"size=abc"`
+}
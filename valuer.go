@@ -0,0 +1,16 @@
+package toyorm
+
+import "github.com/aristletl/toyorm/internal/valuer"
+
+// ValueCreator 创建访问结构体字段的 valuer，通过 DBWithValuer 指定
+type ValueCreator = valuer.Creator
+
+var (
+	// GeneratedValuer 使用 toyorm-gen 生成的代码读写字段，没有生成代码的模型使用 UnsafeValuer，
+	// 默认的实现
+	GeneratedValuer ValueCreator = valuer.NewGeneratedValue
+	// UnsafeValuer 通过字段的偏移量直接读写内存
+	UnsafeValuer ValueCreator = valuer.NewUnsafeValue
+	// ReflectValuer 通过 reflect 读写字段
+	ReflectValuer ValueCreator = valuer.NewReflectValue
+)
@@ -0,0 +1,417 @@
+package toyorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/aristletl/toyorm/internal/model"
+	"github.com/aristletl/toyorm/internal/valuer"
+)
+
+func TestValuer_ScannerAndValuer(t *testing.T) {
+	db, err := Open("sqlite3", "file:valuer.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	require.NoError(t, err)
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE account(id INTEGER PRIMARY KEY, nickname TEXT, " +
+		"balance TEXT, credit TEXT, age INTEGER, tags TEXT);")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	age := 18
+	credit := Money(-50)
+	accounts := []*Account{
+		{
+			Id:       1,
+			Nickname: sql.NullString{String: "tom", Valid: true},
+			Balance:  Money(1234),
+			Credit:   &credit,
+			Age:      &age,
+		},
+		// 指针字段是 nil 的时候写入 NULL
+		{Id: 2, Balance: Money(0)},
+	}
+	require.NoError(t, NewInserter[Account](db).Columns("Id", "Nickname", "Balance", "Credit", "Age").Values(accounts...).Exec(ctx).Err())
+
+	var balance, credit1, credit2 sql.NullString
+	require.NoError(t, db.db.QueryRow("SELECT balance, credit FROM account WHERE id = 1").Scan(&balance, &credit1))
+	assert.Equal(t, "12.34", balance.String)
+	assert.Equal(t, "-0.50", credit1.String)
+	require.NoError(t, db.db.QueryRow("SELECT credit FROM account WHERE id = 2").Scan(&credit2))
+	assert.False(t, credit2.Valid)
+
+	for name, creator := range valuerCreators {
+		t.Run(name, func(t *testing.T) {
+			s := NewSelector[Account](db).Select(Col("Id"), Col("Nickname"), Col("Balance"), Col("Credit"), Col("Age"))
+			s.valCreator = creator
+			got, err := s.GetMulti(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, accounts, got)
+		})
+	}
+}
+
+func TestValuer_UnsupportedType(t *testing.T) {
+	db := memoryDB(t)
+	_, err := NewInserter[Account](db).Values(&Account{Id: 1, Tags: map[string]string{}}).Build()
+	assert.Equal(t, errs.NewErrUnsupportedFieldType("Tags", reflect.TypeOf(map[string]string{})), err)
+
+	_, err = db.db.Exec("CREATE TABLE account(id INTEGER PRIMARY KEY, tags TEXT);")
+	require.NoError(t, err)
+	_, err = db.db.Exec("INSERT INTO account(id, tags) VALUES(1, 'a');")
+	require.NoError(t, err)
+	for name, creator := range valuerCreators {
+		t.Run(name, func(t *testing.T) {
+			s := NewSelector[Account](db).Select(Col("Id"), Col("Tags"))
+			s.valCreator = creator
+			_, err := s.Get(context.Background())
+			assert.Equal(t, errs.NewErrUnsupportedFieldType("Tags", reflect.TypeOf(map[string]string{})), err)
+		})
+	}
+}
+
+// Money 以分为单位，在数据库里存成元。
+// Value 和 Scan 都是指针接收器，作为非指针字段的时候也要能写入
+type Money int64
+
+func (m *Money) Value() (driver.Value, error) {
+	sign := ""
+	v := int64(*m)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100), nil
+}
+
+func (m *Money) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("无法把 %T 转换成 Money", src)
+	}
+	var yuan, fen int64
+	neg := len(s) > 0 && s[0] == '-'
+	if neg {
+		s = s[1:]
+	}
+	if _, err := fmt.Sscanf(s, "%d.%d", &yuan, &fen); err != nil {
+		return err
+	}
+	*m = Money(yuan*100 + fen)
+	if neg {
+		*m = -*m
+	}
+	return nil
+}
+
+type Account struct {
+	Id       int64
+	Nickname sql.NullString
+	Balance  Money
+	Credit   *Money
+	Age      *int
+	Tags     map[string]string
+}
+
+//go:generate go run ./cmd/toyorm-gen -type=ConformanceModel,BenchModel -tests
+
+// valuerCreators 没有生成代码的模型使用 GeneratedValuer 的时候等价于 UnsafeValuer
+var valuerCreators = map[string]ValueCreator{
+	"generated": GeneratedValuer,
+	"unsafe":    UnsafeValuer,
+	"reflect":   ReflectValuer,
+}
+
+// TestValuer_Conformance 两种 valuer 的行为必须完全一致
+func TestValuer_Conformance(t *testing.T) {
+	for name, creator := range valuerCreators {
+		t.Run(name, func(t *testing.T) {
+			testValuerConformance(t, creator)
+		})
+	}
+}
+
+func testValuerConformance(t *testing.T, creator ValueCreator) {
+	r, err := model.NewRegistry(model.WithConverter(reflect.TypeOf(Status(0)), statusConverter{}))
+	require.NoError(t, err)
+	m, err := r.Get(&ConformanceModel{})
+	require.NoError(t, err)
+	now := time.UnixMilli(1700000000000)
+	nickname := "t"
+
+	newEntity := func() *ConformanceModel {
+		return &ConformanceModel{
+			BaseModel: BaseModel{Id: 1, CreatedAt: now},
+			Name:      "tom",
+			Nickname:  &nickname,
+			Balance:   150,
+			Score:     NullOf(1.5),
+			Status:    StatusOnline,
+			Addr:      Address{City: "hz", Street: "xihu"},
+			secret:    "s",
+		}
+	}
+
+	t.Run("Field", func(t *testing.T) {
+		entity := newEntity()
+		val := creator(entity, m)
+		balance := Money(150)
+		wants := []any{int64(1), now, "tom", &nickname, &balance, NullOf(1.5), StatusOnline, "hz", "xihu", "s"}
+		require.Len(t, m.Columns, len(wants))
+		for i, want := range wants {
+			got, err := val.Field(i)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, m.Columns[i].Name)
+		}
+		// nil 指针返回 nil
+		entity.Nickname = nil
+		got, err := val.Field(m.FieldMap["Nickname"].Index)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		_, err = val.Field(len(wants))
+		assert.Equal(t, errs.NewErrUnknownField(""), err)
+		_, err = val.Field(-1)
+		assert.Equal(t, errs.NewErrUnknownField(""), err)
+	})
+
+	t.Run("FieldByName", func(t *testing.T) {
+		val := creator(newEntity(), m)
+		got, err := val.FieldByName("Addr.City")
+		require.NoError(t, err)
+		assert.Equal(t, "hz", got)
+		got, err = val.FieldByName("Id")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), got)
+		_, err = val.FieldByName("Invalid")
+		assert.Equal(t, errs.NewErrUnknownField("Invalid"), err)
+	})
+
+	t.Run("SetField", func(t *testing.T) {
+		entity := newEntity()
+		val := creator(entity, m)
+		require.NoError(t, val.SetField(m.FieldMap["Id"].Index, int32(12)))
+		assert.Equal(t, int64(12), entity.Id)
+		require.NoError(t, val.SetField(m.FieldMap["secret"].Index, "x"))
+		assert.Equal(t, "x", entity.secret)
+		err := val.SetField(m.FieldMap["Name"].Index, []int{1})
+		assert.Equal(t, errs.NewErrUnsupportedFieldValue("Name", []int{1}), err)
+		err = val.SetField(len(m.Columns), 1)
+		assert.Equal(t, errs.NewErrUnknownField(""), err)
+	})
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	cols := []string{"id", "created_at", "name", "nickname", "balance", "score", "status", "addr_city", "addr_street", "secret"}
+	query := func(t *testing.T, rows *sqlmock.Rows) *sql.Rows {
+		mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+		res, err := mockDB.Query("SELECT *")
+		require.NoError(t, err)
+		require.True(t, res.Next())
+		return res
+	}
+
+	t.Run("SetColumns", func(t *testing.T) {
+		rows := query(t, sqlmock.NewRows(cols).
+			AddRow(1, now, "tom", "t", "1.50", 1.5, "online", "hz", "xihu", "s"))
+		defer func() { _ = rows.Close() }()
+		got := &ConformanceModel{}
+		require.NoError(t, creator(got, m).SetColumns(rows))
+		assert.Equal(t, newEntity(), got)
+	})
+
+	t.Run("SetColumns null", func(t *testing.T) {
+		rows := query(t, sqlmock.NewRows([]string{"nickname", "score"}).AddRow(nil, nil))
+		defer func() { _ = rows.Close() }()
+		got := newEntity()
+		require.NoError(t, creator(got, m).SetColumns(rows))
+		assert.Nil(t, got.Nickname)
+		assert.Equal(t, Null[float64]{}, got.Score)
+	})
+
+	t.Run("SetColumns converter error", func(t *testing.T) {
+		rows := query(t, sqlmock.NewRows([]string{"status"}).AddRow("unknown"))
+		defer func() { _ = rows.Close() }()
+		assert.Equal(t, errInvalidStatus, creator(newEntity(), m).SetColumns(rows))
+	})
+
+	t.Run("SetColumns unknown column", func(t *testing.T) {
+		rows := query(t, sqlmock.NewRows([]string{"id", "invalid"}).AddRow(1, 2))
+		defer func() { _ = rows.Close() }()
+		assert.Equal(t, errs.NewErrUnknownColumn("invalid"), creator(newEntity(), m).SetColumns(rows))
+	})
+
+	t.Run("SetColumns too many columns", func(t *testing.T) {
+		many := make([]string, len(cols)+1)
+		vals := make([]driver.Value, len(many))
+		for i := range many {
+			many[i] = fmt.Sprintf("c%d", i)
+		}
+		rows := query(t, sqlmock.NewRows(many).AddRow(vals...))
+		defer func() { _ = rows.Close() }()
+		assert.Equal(t, errs.ErrTooManyReturnedColumns, creator(newEntity(), m).SetColumns(rows))
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGeneratedValuer(t *testing.T) {
+	r, err := model.NewRegistry()
+	require.NoError(t, err)
+	m, err := r.Get(&ConformanceModel{})
+	require.NoError(t, err)
+	assert.True(t, m.Accessor)
+	assert.IsType(t, valuer.GeneratedValue{}, GeneratedValuer(&ConformanceModel{}, m))
+
+	m, err = r.Get(&TestModel{})
+	require.NoError(t, err)
+	assert.False(t, m.Accessor)
+	assert.IsType(t, valuer.UnsafeValue{}, GeneratedValuer(&TestModel{}, m))
+
+	_, err = r.Get(&StaleModel{})
+	assert.Equal(t, errs.NewErrStaleAccessor("stale_model"), err)
+}
+
+func TestDBWithValuer(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	var created []string
+	creator := func(val any, m *model.Model) valuer.Value {
+		created = append(created, m.TableName)
+		return ReflectValuer(val, m)
+	}
+	db, err := OpenDB(mockDB, DBWithValuer(creator))
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "first_name"}).AddRow(1, "Tom"))
+	tm, err := NewSelector[TestModel](db).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Tom", tm.FirstName)
+	assert.NotEmpty(t, created)
+
+	created = nil
+	_, err = NewInserter[TestModel](db).Values(tm).Build()
+	require.NoError(t, err)
+	assert.NotEmpty(t, created)
+
+	created = nil
+	_, err = NewUpdater[TestModel](db).Update(tm).Build()
+	require.NoError(t, err)
+	assert.NotEmpty(t, created)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type ConformanceModel struct {
+	BaseModel
+	Name     string
+	Nickname *string
+	Balance  Money
+	Score    Null[float64]
+	Status   Status
+	Addr     Address `orm:"embedded,prefix=addr_"`
+	secret   string
+}
+
+func BenchmarkValuer_Field(b *testing.B) {
+	r, err := model.NewRegistry()
+	require.NoError(b, err)
+	m, err := r.Get(&BenchModel{})
+	require.NoError(b, err)
+	entity := &BenchModel{Id: 1, Name: "tom", Age: 18, Email: "tom@example.com", Score: 1.5}
+	for name, creator := range valuerCreators {
+		b.Run(name, func(b *testing.B) {
+			val := creator(entity, m)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := range m.Columns {
+					if _, err := val.Field(j); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValuer_SetColumns(b *testing.B) {
+	r, err := model.NewRegistry()
+	require.NoError(b, err)
+	m, err := r.Get(&BenchModel{})
+	require.NoError(b, err)
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(b, err)
+	defer func() { _ = mockDB.Close() }()
+	cols := []string{"id", "name", "age", "email", "nickname", "score", "created_at"}
+	now := time.Now()
+	const rowsPerQuery = 100
+
+	for name, creator := range valuerCreators {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				rows := sqlmock.NewRows(cols)
+				for j := 0; j < rowsPerQuery; j++ {
+					rows.AddRow(int64(j), "tom", int64(18), "tom@example.com", "t", 1.5, now)
+				}
+				mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+				res, err := mockDB.Query("SELECT *")
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				for res.Next() {
+					if err = creator(&BenchModel{}, m).SetColumns(res); err != nil {
+						b.Fatal(err)
+					}
+				}
+				_ = res.Close()
+			}
+		})
+	}
+}
+
+type BenchModel struct {
+	Id        int64
+	Name      string
+	Age       int8
+	Email     string
+	Nickname  *string
+	Score     float64
+	CreatedAt time.Time
+}
+
+// StaleModel 模拟修改了字段之后没有重新生成的代码
+type StaleModel struct {
+	Id   int64
+	Name string
+}
+
+func (s *StaleModel) ToyormFieldNames() []string {
+	return []string{"Id", "FirstName"}
+}
+
+func (s *StaleModel) ToyormField(i int) any {
+	return nil
+}
+
+func (s *StaleModel) ToyormFieldPtr(i int) any {
+	return nil
+}
@@ -0,0 +1,122 @@
+package toyorm
+
+import "strconv"
+
+// WindowFunc 代表窗口函数，例如
+// ROW_NUMBER() OVER (PARTITION BY `x` ORDER BY `y` ASC)
+type WindowFunc struct {
+	fn     string
+	args   []Expression
+	window Window
+	alias  string
+}
+
+func (w WindowFunc) selectable() {}
+
+func (w WindowFunc) Expr() {}
+
+func (w WindowFunc) AS(alias string) WindowFunc {
+	return WindowFunc{
+		fn:     w.fn,
+		args:   w.args,
+		window: w.window,
+		alias:  alias,
+	}
+}
+
+// Over 指定窗口
+func (w WindowFunc) Over(win Window) WindowFunc {
+	return WindowFunc{
+		fn:     w.fn,
+		args:   w.args,
+		window: win,
+		alias:  w.alias,
+	}
+}
+
+// Asc 按照窗口函数的结果升序排列
+func (w WindowFunc) Asc() OrderBy {
+	return OrderBy{
+		expr:  w,
+		order: "ASC",
+	}
+}
+
+// Desc 按照窗口函数的结果降序排列
+func (w WindowFunc) Desc() OrderBy {
+	return OrderBy{
+		expr:  w,
+		order: "DESC",
+	}
+}
+
+// Window 代表 OVER 后面括号里的部分，参数都是字段名
+type Window struct {
+	partitionBy []string
+	orderBy     []OrderBy
+}
+
+func PartitionBy(cols ...string) Window {
+	return Window{
+		partitionBy: cols,
+	}
+}
+
+// WindowOrderBy 只有 ORDER BY 没有 PARTITION BY 的窗口
+func WindowOrderBy(os ...Expression) Window {
+	return Window{
+		orderBy: toOrderBys(os),
+	}
+}
+
+func (w Window) OrderBy(os ...Expression) Window {
+	return Window{
+		partitionBy: w.partitionBy,
+		orderBy:     toOrderBys(os),
+	}
+}
+
+func RowNumber() WindowFunc {
+	return WindowFunc{
+		fn: "ROW_NUMBER",
+	}
+}
+
+func Rank() WindowFunc {
+	return WindowFunc{
+		fn: "RANK",
+	}
+}
+
+func DenseRank() WindowFunc {
+	return WindowFunc{
+		fn: "DENSE_RANK",
+	}
+}
+
+// Lag 取当前行之前第 offset 行的 col 的值。
+// MySQL 要求 offset 是字面量，所以不作为参数传递
+func Lag(col string, offset int) WindowFunc {
+	return WindowFunc{
+		fn:   "LAG",
+		args: []Expression{Col(col), Raw(strconv.Itoa(offset))},
+	}
+}
+
+// Lead 取当前行之后第 offset 行的 col 的值
+func Lead(col string, offset int) WindowFunc {
+	return WindowFunc{
+		fn:   "LEAD",
+		args: []Expression{Col(col), Raw(strconv.Itoa(offset))},
+	}
+}
+
+// Over 把聚合函数作为窗口函数使用，例如 SUM(`amount`) OVER (PARTITION BY `user_id`)
+func (a Aggregate) Over(win Window) WindowFunc {
+	return WindowFunc{
+		fn:     a.fn,
+		args:   []Expression{Col(a.arg)},
+		window: win,
+		alias:  a.alias,
+	}
+}
@@ -0,0 +1,115 @@
+package toyorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aristletl/toyorm/internal/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelector_Window(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "row number",
+			q: NewSelector[TestModel](db).
+				Select(Col("Id"), RowNumber().Over(PartitionBy("Age").OrderBy(Desc("Id"))).AS("rn")),
+			wantQuery: &Query{
+				SQL: "SELECT `id`, ROW_NUMBER() OVER (PARTITION BY `age` ORDER BY `id` DESC) AS `rn` FROM `test_model`;",
+			},
+		},
+		{
+			name: "rank without partition",
+			q: NewSelector[TestModel](db).
+				Select(Rank().Over(WindowOrderBy(Asc("Age"))), DenseRank().Over(Window{})),
+			wantQuery: &Query{
+				SQL: "SELECT RANK() OVER (ORDER BY `age` ASC), DENSE_RANK() OVER () FROM `test_model`;",
+			},
+		},
+		{
+			name: "lag lead",
+			q: NewSelector[TestModel](db).
+				Select(Lag("Age", 1).Over(WindowOrderBy(Asc("Id"))).AS("prev_age"),
+					Lead("Age", 2).Over(WindowOrderBy(Asc("Id"))).AS("next_age")),
+			wantQuery: &Query{
+				SQL: "SELECT LAG(`age`, 1) OVER (ORDER BY `id` ASC) AS `prev_age`, " +
+					"LEAD(`age`, 2) OVER (ORDER BY `id` ASC) AS `next_age` FROM `test_model`;",
+			},
+		},
+		{
+			name: "aggregate over",
+			q: NewSelector[TestModel](db).
+				Select(Sum("Age").Over(PartitionBy("FirstName", "LastName")).AS("total")),
+			wantQuery: &Query{
+				SQL: "SELECT SUM(`age`) OVER (PARTITION BY `first_name`, `last_name`) AS `total` FROM `test_model`;",
+			},
+		},
+		{
+			name: "order by",
+			q: NewSelector[TestModel](db).
+				OrderBy(RowNumber().Over(PartitionBy("Age").OrderBy(Asc("Id"))).Desc(), Asc("Id")),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` ORDER BY ROW_NUMBER() OVER (PARTITION BY `age` ORDER BY `id` ASC) DESC, `id` ASC;",
+			},
+		},
+		{
+			name:    "invalid partition column",
+			q:       NewSelector[TestModel](db).Select(RowNumber().Over(PartitionBy("Invalid"))),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name:    "invalid argument",
+			q:       NewSelector[TestModel](db).Select(Lag("Invalid", 1).Over(Window{})),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSelector_Window_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", "file:window.db?cache=shared&mode=memory", DBWithDialect(SQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.db.Close() }()
+	_, err = db.db.Exec("CREATE TABLE score(id INTEGER PRIMARY KEY, class INTEGER, val INTEGER);" +
+		"INSERT INTO score VALUES (1, 1, 80), (2, 1, 90), (3, 2, 70), (4, 2, 60);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewSelector[Score](db).
+		Select(Col("Id"), RowNumber().Over(PartitionBy("Class").OrderBy(Desc("Val"))).AS("rn")).
+		OrderBy(Asc("Id")).
+		GetMulti(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []*Score{
+		{Id: 1, Rn: 2},
+		{Id: 2, Rn: 1},
+		{Id: 3, Rn: 1},
+		{Id: 4, Rn: 2},
+	}, res)
+}
+
+type Score struct {
+	Id    int64
+	Class int64
+	Val   int64
+	Rn    int64
+}